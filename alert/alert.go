@@ -38,8 +38,28 @@ func (c *client) Close() {
 	}
 }
 
+// Option configures client.
+type Option func(*client)
+
+// WithConn makes client use established connection instead of dialing Ino-Vibe server.
+// Credentials are not loaded and connection is not closed by client.
+func WithConn(conn grpc.ClientConnInterface) Option {
+	return func(c *client) {
+		c.alertClient = pb.NewAlertServiceClient(conn)
+	}
+}
+
 // NewClient creates client.
-func NewClient() (Client, error) {
+func NewClient(opts ...Option) (Client, error) {
+	c := &client{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.alertClient != nil {
+		return c, nil
+	}
+
 	token, err := iv_auth.LoadCredentials()
 	if err != nil {
 		return nil, err
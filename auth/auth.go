@@ -2,16 +2,16 @@ package auth
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
-	"os/user"
+	"path/filepath"
 	"time"
 
-	jwt "github.com/dgrijalva/jwt-go"
 	"golang.org/x/oauth2"
 )
 
@@ -21,6 +21,18 @@ type storedCredential struct {
 	ExpireIn    int    `json:"expire_in"`
 }
 
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Default values of token request.
+const (
+	DefaultTokenURL = "https://ino-vibe.auth0.com/oauth/token"
+	DefaultAudience = "https://grpc.ino-vibe.ino-on.dev"
+)
+
 var (
 	credFilePath string
 )
@@ -29,21 +41,79 @@ func init() {
 	credFilePath = os.Getenv("INOVIBE_APPLICATION_CREDENTIALS")
 }
 
-// IssueCredentials requests new credentials to Auth0.
+func credentialsPath() string {
+	if credFilePath == "" {
+		home := os.Getenv("HOME")
+		credFilePath = home + "/.inovibe/credentials.json"
+	}
+
+	return credFilePath
+}
+
+// TokenError describes rejected token request.
+type TokenError struct {
+	StatusCode  int
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+	Body        []byte `json:"-"`
+}
+
+func (e *TokenError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("Auth failed: %d %s", e.StatusCode, string(e.Body))
+	}
+
+	return fmt.Sprintf("Auth failed: %d %s: %s", e.StatusCode, e.Code, e.Description)
+}
+
+type tokenConfig struct {
+	tokenURL   string
+	audience   string
+	httpClient *http.Client
+	timeout    time.Duration
+}
+
+// Option changes configuration of token request.
+type Option func(*tokenConfig)
+
+// WithTokenURL sets token endpoint of Auth0 tenant.
+func WithTokenURL(url string) Option {
+	return func(c *tokenConfig) {
+		c.tokenURL = url
+	}
+}
+
+// WithAudience sets API audience of token.
+func WithAudience(audience string) Option {
+	return func(c *tokenConfig) {
+		c.audience = audience
+	}
+}
+
+// WithHTTPClient sets HTTP client which sends token request.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *tokenConfig) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets time limit of token request.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *tokenConfig) {
+		c.timeout = timeout
+	}
+}
+
+// IssueCredentials requests new credentials to Auth0 without storing them.
 func IssueCredentials(id, secret, audience string) (*oauth2.Token, error) {
-	return nil, nil
+	return RequestToken(context.Background(), id, secret, WithAudience(audience))
 }
 
 // LoadCredentials loads credential file from local storage.
 func LoadCredentials() (*oauth2.Token, error) {
 	storedCred := storedCredential{}
 
-	if credFilePath == "" {
-		home := os.Getenv("HOME")
-		credFilePath = home + "/.inovibe/credentials.json"
-	}
-
-	credData, err := ioutil.ReadFile(credFilePath)
+	credData, err := ioutil.ReadFile(credentialsPath())
 	if err != nil {
 		log.Println("LoadCredentials", err)
 		return nil, err
@@ -55,30 +125,65 @@ func LoadCredentials() (*oauth2.Token, error) {
 		return nil, err
 	}
 
-	claims := jwt.MapClaims{}
-	_, err = jwt.ParseWithClaims(storedCred.AccessToken, claims,
-		func(token *jwt.Token) (interface{}, error) {
-			return nil, nil
-		})
+	claims, err := ParseClaims(storedCred.AccessToken)
+	if err != nil {
+		log.Println("LoadCredentials", err)
+		return nil, err
+	}
 
-	expTime := time.Unix(int64(claims["exp"].(float64)), 0)
+	if claims.ExpiresAt.IsZero() {
+		return nil, ErrNoExpiry
+	}
 
 	oauthToken := &oauth2.Token{
 		AccessToken: storedCred.AccessToken,
 		TokenType:   storedCred.TokenType,
-		Expiry:      expTime,
+		Expiry:      claims.ExpiresAt,
 	}
 
 	return oauthToken, nil
 }
 
-// IssueToken issues new OAuth2 token.
+// IssueToken issues new OAuth2 token and stores it as local credentials.
 func IssueToken(clientID, clientSecret, audience string) (*oauth2.Token, error) {
-	// Issue token
+	oauthToken, err := RequestToken(context.Background(), clientID, clientSecret, WithAudience(audience))
+	if err != nil {
+		return nil, err
+	}
+
+	err = SaveCredentials(oauthToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return oauthToken, nil
+}
+
+// RequestToken requests new OAuth2 token with client credentials grant.
+// Token endpoint, audience, HTTP client and timeout can be changed by options.
+//
+// TokenError returns if Auth0 rejects the request.
+func RequestToken(ctx context.Context, clientID, clientSecret string, opts ...Option) (*oauth2.Token, error) {
+	cfg := tokenConfig{
+		tokenURL:   DefaultTokenURL,
+		audience:   DefaultAudience,
+		httpClient: http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
+
 	cred := map[string]string{
 		"client_id":     clientID,
 		"client_secret": clientSecret,
-		"audience":      audience,
+		"audience":      cfg.audience,
 		"grant_type":    "client_credentials",
 	}
 
@@ -87,32 +192,31 @@ func IssueToken(clientID, clientSecret, audience string) (*oauth2.Token, error)
 		return nil, err
 	}
 
-	req, err := http.NewRequest(
-		http.MethodPost,
-		"https://ino-vibe.auth0.com/oauth/token",
-		bytes.NewReader(credData))
+	req, err := http.NewRequest(http.MethodPost, cfg.tokenURL, bytes.NewReader(credData))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Add("Content-Type", "application/json")
 
-	c := http.Client{}
-	resp, err := c.Do(req)
+	resp, err := cfg.httpClient.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("Auth failed")
-	}
+	defer resp.Body.Close()
 
 	respData, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	newCred := storedCredential{}
+	if resp.StatusCode != http.StatusOK {
+		tokenErr := &TokenError{StatusCode: resp.StatusCode, Body: respData}
+		_ = json.Unmarshal(respData, tokenErr)
+		return nil, tokenErr
+	}
+
+	newCred := tokenResponse{}
 	err = json.Unmarshal(respData, &newCred)
 	if err != nil {
 		return nil, err
@@ -123,16 +227,43 @@ func IssueToken(clientID, clientSecret, audience string) (*oauth2.Token, error)
 		TokenType:   newCred.TokenType,
 	}
 
-	// Store
-	u, err := user.Current()
-	_ = os.Mkdir(u.HomeDir+"/.inovibe", 0744)
+	if newCred.ExpiresIn > 0 {
+		oauthToken.Expiry = time.Now().Add(time.Duration(newCred.ExpiresIn) * time.Second)
+	}
+
+	return oauthToken, nil
+}
+
+// SaveCredentials stores token into local credential file which LoadCredentials reads.
+// File is readable by owner only because it has bearer token.
+func SaveCredentials(token *oauth2.Token) error {
+	storedCred := storedCredential{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+	}
+
+	if !token.Expiry.IsZero() {
+		storedCred.ExpireIn = int(time.Until(token.Expiry).Seconds())
+	}
 
-	err = ioutil.WriteFile(u.HomeDir+"/.inovibe/credentials.json", respData, 0644)
+	credData, err := json.Marshal(storedCred)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return oauthToken, nil
+	path := credentialsPath()
+	err = os.MkdirAll(filepath.Dir(path), 0744)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(path, credData, 0600)
+	if err != nil {
+		return err
+	}
+
+	// WriteFile keeps mode of existing file.
+	return os.Chmod(path, 0600)
 }
 
 // IsValidToken checks wheather received token is valid or not.
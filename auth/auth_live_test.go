@@ -0,0 +1,38 @@
+//go:build live
+// +build live
+
+package auth
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthLoadSuccess(t *testing.T) {
+	credFilePath = os.Getenv("INOVIBE_APPLICATION_CREDENTIALS")
+	oauthToken, err := LoadCredentials()
+
+	assert.NotNil(t, oauthToken)
+	assert.Nil(t, err)
+}
+
+func TestAuthIssueToken(t *testing.T) {
+	clientID := "O9so4gOpXmnC6pUHc5rOeslkUA2bXgLK"
+	clientSecret := "AV5xpMVFt93uvvPHsBHvB8nJERnamLYxOkBreqWptRSEDcS8QDUmflgMPQVVR5Hv"
+	audience := "https://grpc.ino-vibe.ino-on.dev"
+
+	token, err := IssueToken(clientID, clientSecret, audience)
+
+	assert.NotNil(t, token)
+	assert.Nil(t, err)
+}
+
+func TestAuthCheckTokenValid(t *testing.T) {
+	token, _ := LoadCredentials()
+
+	fmt.Println(token)
+
+}
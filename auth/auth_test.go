@@ -1,11 +1,17 @@
 package auth
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
 )
 
 func TestAuthNoCredentialFile(t *testing.T) {
@@ -34,14 +40,6 @@ func TestAuthInvalidCredentialFormat(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
-func TestAuthLoadSuccess(t *testing.T) {
-	credFilePath = os.Getenv("INOVIBE_APPLICATION_CREDENTIALS")
-	oauthToken, err := LoadCredentials()
-
-	assert.NotNil(t, oauthToken)
-	assert.Nil(t, err)
-}
-
 func TestAuthDefaultFilePath(t *testing.T) {
 	credFilePath = ""
 	_, _ = LoadCredentials()
@@ -50,20 +48,90 @@ func TestAuthDefaultFilePath(t *testing.T) {
 	assert.Equal(t, home+"/.inovibe/credentials.json", credFilePath)
 }
 
-func TestAuthIssueToken(t *testing.T) {
-	clientID := "O9so4gOpXmnC6pUHc5rOeslkUA2bXgLK"
-	clientSecret := "AV5xpMVFt93uvvPHsBHvB8nJERnamLYxOkBreqWptRSEDcS8QDUmflgMPQVVR5Hv"
-	audience := "https://grpc.ino-vibe.ino-on.dev"
+func TestAuthCredentialWithoutExpiry(t *testing.T) {
+	credFilePath = "./credentials.json"
+	token := "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJzdWIiOiJ0ZXN0In0."
+	err := ioutil.WriteFile(credFilePath, []byte(`{"access_token":"`+token+`","token_type":"Bearer"}`), 0644)
+	if err != nil {
+		panic(err)
+	}
+
+	defer os.Remove(credFilePath)
+
+	oauthToken, err := LoadCredentials()
+
+	assert.Nil(t, oauthToken)
+	assert.Equal(t, ErrNoExpiry, err)
+}
+
+func TestAuthSaveCredentialsMode(t *testing.T) {
+	credFilePath = "./credentials.json"
+	err := ioutil.WriteFile(credFilePath, []byte("{}"), 0644)
+	if err != nil {
+		panic(err)
+	}
 
-	token, err := IssueToken(clientID, clientSecret, audience)
+	defer os.Remove(credFilePath)
 
-	assert.NotNil(t, token)
+	err = SaveCredentials(&oauth2.Token{AccessToken: "token", TokenType: "Bearer", Expiry: time.Now().Add(time.Hour)})
 	assert.Nil(t, err)
+
+	info, err := os.Stat(credFilePath)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
 }
 
-func TestAuthCheckTokenValid(t *testing.T) {
-	token, _ := LoadCredentials()
+func TestAuthRequestToken(t *testing.T) {
+	var received map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+
+		if received["client_secret"] != "valid-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"access_denied","error_description":"Unauthorized"}`))
+			return
+		}
 
-	fmt.Println(token)
+		_, _ = w.Write([]byte(`{"access_token":"new-token","token_type":"Bearer","expires_in":86400}`))
+	}))
+	defer server.Close()
 
+	ctx := context.Background()
+
+	token, err := RequestToken(ctx, "client-id", "valid-secret",
+		WithTokenURL(server.URL),
+		WithAudience("https://stage-grpc.ino-vibe.ino-on.dev"),
+		WithHTTPClient(server.Client()),
+		WithTimeout(time.Second))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "new-token", token.AccessToken)
+	assert.Equal(t, "Bearer", token.TokenType)
+	assert.InDelta(t, time.Now().Add(24*time.Hour).Unix(), token.Expiry.Unix(), 5)
+	assert.Equal(t, "https://stage-grpc.ino-vibe.ino-on.dev", received["audience"])
+	assert.Equal(t, "client_credentials", received["grant_type"])
+
+	token, err = RequestToken(ctx, "client-id", "wrong-secret", WithTokenURL(server.URL))
+
+	assert.Nil(t, token)
+	tokenErr, ok := err.(*TokenError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, tokenErr.StatusCode)
+	assert.Equal(t, "access_denied", tokenErr.Code)
+	assert.Equal(t, "Unauthorized", tokenErr.Description)
+}
+
+func TestAuthRequestTokenTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	token, err := RequestToken(context.Background(), "client-id", "secret",
+		WithTokenURL(server.URL),
+		WithTimeout(10*time.Millisecond))
+
+	assert.Nil(t, token)
+	assert.NotNil(t, err)
 }
@@ -0,0 +1,289 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// ClaimNamespace is prefix of custom claims which Ino-Vibe Auth0 rules add into access token.
+const ClaimNamespace = "https://ino-vibe.ino-on.dev/"
+
+// DefaultJWKSURL is location of key set which signs Ino-Vibe access tokens.
+const DefaultJWKSURL = "https://ino-vibe.auth0.com/.well-known/jwks.json"
+
+// DefaultIssuer is issuer of Ino-Vibe access tokens.
+const DefaultIssuer = "https://ino-vibe.auth0.com/"
+
+// Errors
+var (
+	ErrInvalidToken      = errors.New("Invalid access token")
+	ErrNoExpiry          = errors.New("Access token has no expiry")
+	ErrTokenExpired      = errors.New("Access token is expired")
+	ErrUnknownSigningKey = errors.New("Signing key is not found in key set")
+	ErrInvalidAudience   = errors.New("Access token is not issued for audience")
+	ErrInvalidIssuer     = errors.New("Access token is not issued by issuer")
+)
+
+// Claims describes claims granted by access token.
+type Claims struct {
+	Issuer      string
+	Subject     string
+	Audience    []string
+	Scopes      []string
+	Permissions []string
+
+	// GroupID is tenant group of token owner.
+	GroupID string
+	// Groups is list of group IDs which token owner can access.
+	Groups []string
+	// Custom contains every namespaced claim keyed by name without ClaimNamespace.
+	Custom map[string]interface{}
+
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// HasScope checks whether claims grant selected scope.
+func (c *Claims) HasScope(scope string) bool {
+	return contains(c.Scopes, scope)
+}
+
+// HasPermission checks whether claims grant selected permission.
+func (c *Claims) HasPermission(permission string) bool {
+	return contains(c.Permissions, permission)
+}
+
+// HasAudience checks whether token is issued for selected audience.
+func (c *Claims) HasAudience(audience string) bool {
+	return contains(c.Audience, audience)
+}
+
+// InGroup checks whether selected group is accessible with claims.
+func (c *Claims) InGroup(groupID string) bool {
+	return c.GroupID == groupID || contains(c.Groups, groupID)
+}
+
+// Expired checks whether token is expired.
+// Token without expiry is never expired.
+func (c *Claims) Expired() bool {
+	return !c.ExpiresAt.IsZero() && !c.ExpiresAt.After(time.Now())
+}
+
+func contains(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseClaims reads claims of access token without verifying signature.
+// Use VerifyClaims if token is received from untrusted source.
+func ParseClaims(accessToken string) (*Claims, error) {
+	mapClaims := jwt.MapClaims{}
+	parser := jwt.Parser{}
+	_, _, err := parser.ParseUnverified(accessToken, mapClaims)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return newClaims(mapClaims), nil
+}
+
+// VerifyClaims verifies signature of access token with keys and returns its claims.
+// Token should be issued by issuer for audience, e.g. DefaultIssuer and DefaultAudience.
+//
+// ErrUnknownSigningKey returns if key ID of token does not exist in keys.
+// ErrTokenExpired returns if token is expired.
+// ErrInvalidIssuer returns if token is issued by other issuer.
+// ErrInvalidAudience returns if token is not issued for audience.
+// ErrInvalidToken returns if token is malformed or signature is not matched.
+func VerifyClaims(accessToken string, keys KeySet, audience, issuer string) (*Claims, error) {
+	mapClaims := jwt.MapClaims{}
+	parser := jwt.Parser{
+		ValidMethods:         []string{jwt.SigningMethodRS256.Alg()},
+		SkipClaimsValidation: true,
+	}
+
+	_, err := parser.ParseWithClaims(accessToken, mapClaims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return keys.Key(kid)
+	})
+	if err != nil {
+		if verr, ok := err.(*jwt.ValidationError); ok && verr.Inner == ErrUnknownSigningKey {
+			return nil, ErrUnknownSigningKey
+		}
+		return nil, ErrInvalidToken
+	}
+
+	claims := newClaims(mapClaims)
+	if claims.Expired() {
+		return nil, ErrTokenExpired
+	}
+
+	if claims.Issuer != issuer {
+		return nil, ErrInvalidIssuer
+	}
+
+	if !claims.HasAudience(audience) {
+		return nil, ErrInvalidAudience
+	}
+
+	return claims, nil
+}
+
+func newClaims(m jwt.MapClaims) *Claims {
+	claims := &Claims{
+		Custom: map[string]interface{}{},
+	}
+
+	claims.Issuer, _ = m["iss"].(string)
+	claims.Subject, _ = m["sub"].(string)
+	claims.Audience = stringList(m["aud"])
+	claims.Permissions = stringList(m["permissions"])
+	if scope, ok := m["scope"].(string); ok {
+		claims.Scopes = strings.Fields(scope)
+	}
+
+	if iat, ok := m["iat"].(float64); ok {
+		claims.IssuedAt = time.Unix(int64(iat), 0)
+	}
+
+	if exp, ok := m["exp"].(float64); ok {
+		claims.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+
+	for k, v := range m {
+		if strings.HasPrefix(k, ClaimNamespace) {
+			claims.Custom[strings.TrimPrefix(k, ClaimNamespace)] = v
+		}
+	}
+
+	claims.GroupID, _ = claims.Custom["group_id"].(string)
+	claims.Groups = stringList(claims.Custom["groups"])
+
+	return claims
+}
+
+// stringList converts claim value which can be either single string or array.
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return []string{}
+	}
+}
+
+// KeySet provides public keys to verify access token.
+type KeySet interface {
+	Key(kid string) (*rsa.PublicKey, error)
+}
+
+// JWK is single key of JSON Web Key Set.
+type JWK struct {
+	Kty string   `json:"kty"`
+	Kid string   `json:"kid"`
+	Use string   `json:"use"`
+	Alg string   `json:"alg"`
+	N   string   `json:"n"`
+	E   string   `json:"e"`
+	X5c []string `json:"x5c,omitempty"`
+}
+
+// JWKS is JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Key returns RSA public key of selected key ID.
+// Only RSA keys for signature are used, and algorithm of key should be RS256 if it is set.
+func (s *JWKS) Key(kid string) (*rsa.PublicKey, error) {
+	for _, key := range s.Keys {
+		if key.Kid != kid || key.Kty != "RSA" || key.Use != "sig" {
+			continue
+		}
+
+		if key.Alg != "" && key.Alg != jwt.SigningMethodRS256.Alg() {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	}
+
+	return nil, ErrUnknownSigningKey
+}
+
+// ParseJWKS parses JSON encoded key set.
+func ParseJWKS(data []byte) (*JWKS, error) {
+	keys := JWKS{}
+	err := json.Unmarshal(data, &keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keys, nil
+}
+
+// FetchJWKS downloads key set from url.
+// http.DefaultClient is used if httpClient is nil.
+func FetchJWKS(ctx context.Context, url string, httpClient *http.Client) (*JWKS, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Fetch JWKS failed: %s", resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJWKS(data)
+}
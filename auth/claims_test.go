@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKeySet(t *testing.T, kid string) (*rsa.PrivateKey, *JWKS) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := &JWKS{
+		Keys: []JWK{
+			{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: "RS256",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			},
+		},
+	}
+
+	return key, keys
+}
+
+func signTestToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func testMapClaims(exp time.Time) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":                       "https://ino-vibe.auth0.com/",
+		"sub":                       "client@clients",
+		"aud":                       []interface{}{"https://grpc.ino-vibe.ino-on.dev", "https://ino-vibe.auth0.com/userinfo"},
+		"scope":                     "read:device write:device",
+		"permissions":               []interface{}{"read:group"},
+		"iat":                       float64(exp.Add(-time.Hour).Unix()),
+		"exp":                       float64(exp.Unix()),
+		ClaimNamespace + "group_id": "0bee7b43-0b57-4b54-9062-430e2bd3fa79",
+		ClaimNamespace + "groups":   []interface{}{"child-group"},
+		ClaimNamespace + "role":     "admin",
+	}
+}
+
+func withClaim(claims jwt.MapClaims, name string, value interface{}) jwt.MapClaims {
+	claims[name] = value
+	return claims
+}
+
+func TestClaimsParse(t *testing.T) {
+	key, _ := newTestKeySet(t, "test-kid")
+	exp := time.Now().Add(time.Hour)
+
+	claims, err := ParseClaims(signTestToken(t, key, "test-kid", testMapClaims(exp)))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "client@clients", claims.Subject)
+	assert.True(t, claims.HasAudience("https://grpc.ino-vibe.ino-on.dev"))
+	assert.True(t, claims.HasScope("write:device"))
+	assert.False(t, claims.HasScope("write:group"))
+	assert.True(t, claims.HasPermission("read:group"))
+	assert.True(t, claims.InGroup("0bee7b43-0b57-4b54-9062-430e2bd3fa79"))
+	assert.True(t, claims.InGroup("child-group"))
+	assert.False(t, claims.InGroup("other-group"))
+	assert.Equal(t, "admin", claims.Custom["role"])
+	assert.Equal(t, exp.Unix(), claims.ExpiresAt.Unix())
+	assert.False(t, claims.Expired())
+}
+
+func TestClaimsParseInvalid(t *testing.T) {
+	claims, err := ParseClaims("not-a-token")
+
+	assert.Nil(t, claims)
+	assert.Equal(t, ErrInvalidToken, err)
+}
+
+func TestClaimsVerify(t *testing.T) {
+	key, keys := newTestKeySet(t, "test-kid")
+	otherKey, _ := newTestKeySet(t, "test-kid")
+
+	tests := []struct {
+		Desc      string
+		Token     string
+		ExpectErr error
+	}{
+		{
+			Desc:      "Successful",
+			Token:     signTestToken(t, key, "test-kid", testMapClaims(time.Now().Add(time.Hour))),
+			ExpectErr: nil,
+		},
+		{
+			Desc:      "Expired",
+			Token:     signTestToken(t, key, "test-kid", testMapClaims(time.Now().Add(-time.Hour))),
+			ExpectErr: ErrTokenExpired,
+		},
+		{
+			Desc:      "Unknown key ID",
+			Token:     signTestToken(t, key, "unknown-kid", testMapClaims(time.Now().Add(time.Hour))),
+			ExpectErr: ErrUnknownSigningKey,
+		},
+		{
+			Desc:      "Signature mismatch",
+			Token:     signTestToken(t, otherKey, "test-kid", testMapClaims(time.Now().Add(time.Hour))),
+			ExpectErr: ErrInvalidToken,
+		},
+		{
+			Desc:      "Other issuer",
+			Token:     signTestToken(t, key, "test-kid", withClaim(testMapClaims(time.Now().Add(time.Hour)), "iss", "https://other.auth0.com/")),
+			ExpectErr: ErrInvalidIssuer,
+		},
+		{
+			Desc:      "Other audience",
+			Token:     signTestToken(t, key, "test-kid", withClaim(testMapClaims(time.Now().Add(time.Hour)), "aud", "https://other.example.com")),
+			ExpectErr: ErrInvalidAudience,
+		},
+	}
+
+	for _, test := range tests {
+		claims, err := VerifyClaims(test.Token, keys, DefaultAudience, DefaultIssuer)
+
+		assert.Equal(t, test.ExpectErr, err, test.Desc)
+		if test.ExpectErr == nil {
+			assert.Equal(t, "client@clients", claims.Subject)
+		}
+	}
+}
+
+func TestClaimsParseJWKS(t *testing.T) {
+	data := []byte(`{"keys":[{"kty":"RSA","kid":"abc","use":"sig","alg":"RS256","n":"AQAB","e":"AQAB"}]}`)
+
+	keys, err := ParseJWKS(data)
+	assert.Nil(t, err)
+
+	pubKey, err := keys.Key("abc")
+	assert.Nil(t, err)
+	assert.Equal(t, 65537, pubKey.E)
+
+	_, err = keys.Key("non-exist")
+	assert.Equal(t, ErrUnknownSigningKey, err)
+
+	// Keys which are not for RS256 signature are ignored.
+	data = []byte(`{"keys":[{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"},{"kty":"RSA","kid":"rs512","use":"sig","alg":"RS512","n":"AQAB","e":"AQAB"}]}`)
+
+	keys, err = ParseJWKS(data)
+	assert.Nil(t, err)
+
+	_, err = keys.Key("enc")
+	assert.Equal(t, ErrUnknownSigningKey, err)
+	_, err = keys.Key("rs512")
+	assert.Equal(t, ErrUnknownSigningKey, err)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+
+	"github.com/rootwarp/ino-vibe-go-sdk/alert"
+)
+
+func runAlertsList(ctx context.Context, e *env, args []string) error {
+	flags := flag.NewFlagSet("alerts list", flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+
+	devid := flags.String("devid", "", "Device ID")
+	groupID := flags.String("group", "", "Group ID")
+	session := flags.String("session", "", "Install session key")
+	offset := flags.Uint("offset", 0, "Offset of alerts")
+	maxCount := flags.Uint("max", 10, "Max count of alerts")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	req := &pb.AlertListRequest{
+		Offset:   uint32(*offset),
+		MaxCount: uint32(*maxCount),
+	}
+
+	switch {
+	case *devid != "":
+		req.Search = &pb.AlertListRequest_Devid{Devid: *devid}
+	case *groupID != "":
+		req.Search = &pb.AlertListRequest_Groupid{Groupid: *groupID}
+	case *session != "":
+		req.Search = &pb.AlertListRequest_InstallSessionKey{InstallSessionKey: *session}
+	default:
+		return ErrMissingArgs
+	}
+
+	if err := checkCredentials(); err != nil {
+		return err
+	}
+
+	cli, err := alert.NewClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	resp, err := cli.List(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"ALERT ID", "DEVID", "TYPE", "ISSUED", "GROUP", "WAVE ID"}}
+	for _, a := range resp.Alerts {
+		t.append(a.Alertid, a.Devid, a.Type, formatTimestamp(a.Issued), a.GetAlarmGroup().GetName(), a.Waveid)
+	}
+
+	return e.render(resp.Alerts, t)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"os"
+	"strings"
+	"time"
+
+	iv_auth "github.com/rootwarp/ino-vibe-go-sdk/auth"
+)
+
+// Environment variables for login.
+const (
+	envClientID     = "INOVIBE_CLIENT_ID"
+	envClientSecret = "INOVIBE_CLIENT_SECRET"
+	envAudience     = "INOVIBE_AUDIENCE"
+)
+
+func runLogin(ctx context.Context, e *env, args []string) error {
+	flags := flag.NewFlagSet("login", flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+
+	clientID := flags.String("client-id", os.Getenv(envClientID), "Client ID, or $"+envClientID)
+	clientSecret := flags.String("client-secret", os.Getenv(envClientSecret), "Client secret, or $"+envClientSecret+", or read from stdin")
+	audience := flags.String("audience", envOrDefault(envAudience, iv_auth.DefaultAudience), "API audience, or $"+envAudience)
+	tokenURL := flags.String("token-url", iv_auth.DefaultTokenURL, "Auth0 token endpoint")
+	timeout := flags.Duration("timeout", 30*time.Second, "Request timeout")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *clientID == "" {
+		return ErrMissingArgs
+	}
+
+	if *clientSecret == "" {
+		e.printf("Client secret: ")
+		secret, err := bufio.NewReader(e.stdin).ReadString('\n')
+		if err != nil && secret == "" {
+			return err
+		}
+		*clientSecret = strings.TrimSpace(secret)
+	}
+
+	token, err := iv_auth.RequestToken(ctx, *clientID, *clientSecret,
+		iv_auth.WithTokenURL(*tokenURL),
+		iv_auth.WithAudience(*audience),
+		iv_auth.WithTimeout(*timeout))
+	if err != nil {
+		return err
+	}
+
+	err = iv_auth.SaveCredentials(token)
+	if err != nil {
+		return err
+	}
+
+	result := struct {
+		TokenType string    `json:"token_type"`
+		Expiry    time.Time `json:"expiry"`
+	}{token.TokenType, token.Expiry}
+
+	t := &table{header: []string{"TOKEN TYPE", "EXPIRY"}}
+	t.append(result.TokenType, result.Expiry.Local().Format(time.RFC3339))
+
+	return e.render(result, t)
+}
+
+func runTokenShow(ctx context.Context, e *env, args []string) error {
+	token, err := iv_auth.LoadCredentials()
+	if err != nil {
+		return err
+	}
+
+	claims, err := iv_auth.ParseClaims(token.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"CLAIM", "VALUE"}}
+	t.append("subject", claims.Subject)
+	t.append("issuer", claims.Issuer)
+	t.append("audience", strings.Join(claims.Audience, " "))
+	t.append("scopes", strings.Join(claims.Scopes, " "))
+	t.append("permissions", strings.Join(claims.Permissions, " "))
+	t.append("group_id", claims.GroupID)
+	t.append("groups", strings.Join(claims.Groups, " "))
+	t.append("expires_at", claims.ExpiresAt.Local().Format(time.RFC3339))
+	t.append("valid", iv_auth.IsValidToken(token))
+
+	return e.render(claims, t)
+}
+
+func envOrDefault(key, defaultValue string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return defaultValue
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+
+	iv_auth "github.com/rootwarp/ino-vibe-go-sdk/auth"
+	"github.com/rootwarp/ino-vibe-go-sdk/device"
+)
+
+// checkCredentials returns error instead of SDK clients panic on missing credentials.
+func checkCredentials() error {
+	token, err := iv_auth.LoadCredentials()
+	if err != nil {
+		return fmt.Errorf("Load credentials failed, run 'inovibe login' first: %v", err)
+	}
+
+	if !iv_auth.IsValidToken(token) {
+		return fmt.Errorf("Credentials are expired, run 'inovibe login' again")
+	}
+
+	return nil
+}
+
+func newDeviceClient() (device.Client, error) {
+	if err := checkCredentials(); err != nil {
+		return nil, err
+	}
+	return device.NewClient()
+}
+
+func parseInstallStatus(s string) (pb.InstallStatus, error) {
+	for name, value := range pb.InstallStatus_value {
+		if strings.EqualFold(name, s) {
+			return pb.InstallStatus(value), nil
+		}
+	}
+	return pb.InstallStatus_Initial, fmt.Errorf("Unknown install status %q", s)
+}
+
+func deviceTable(devs []*pb.Device) *table {
+	t := &table{header: []string{"DEVID", "ALIAS", "GROUP", "STATUS", "BATTERY", "TEMP", "RSSI", "UPDATED"}}
+	for _, dev := range devs {
+		t.append(dev.Devid, dev.Alias, dev.GroupId, dev.InstallStatus, dev.Battery, dev.Temperature, dev.Rssi, formatTimestamp(dev.UpdateDate))
+	}
+	return t
+}
+
+func runDevicesList(ctx context.Context, e *env, args []string) error {
+	flags := flag.NewFlagSet("devices list", flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+
+	status := flags.String("status", "", "Install status filter, e.g. Installed")
+	groupID := flags.String("group", "", "Group ID filter")
+	alias := flags.String("alias", "", "Show devices whose alias contains text")
+	batteryBelow := flags.Uint("battery-below", 0, "Show devices whose battery is less than percent")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	q := device.Query()
+	if *status != "" {
+		installStatus, err := parseInstallStatus(*status)
+		if err != nil {
+			return err
+		}
+		q.WithStatus(installStatus)
+	}
+
+	if *groupID != "" {
+		q.InGroup(*groupID)
+	}
+
+	if *alias != "" {
+		q.Alias(*alias)
+	}
+
+	if *batteryBelow > 0 {
+		q.BatteryBelow(uint32(*batteryBelow))
+	}
+
+	cli, err := newDeviceClient()
+	if err != nil {
+		return err
+	}
+
+	devs, err := q.Run(ctx, cli)
+	if err != nil {
+		return err
+	}
+
+	return e.render(devs, deviceTable(devs))
+}
+
+func runDevicesDetail(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return ErrMissingArgs
+	}
+
+	cli, err := newDeviceClient()
+	if err != nil {
+		return err
+	}
+
+	resp, err := cli.Detail(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	if resp.ResultCode != pb.ResponseCode_SUCCESS || len(resp.Devices) == 0 {
+		return device.ErrNonExistDevice
+	}
+
+	dev := resp.Devices[0]
+
+	t := &table{header: []string{"FIELD", "VALUE"}}
+	t.append("devid", dev.Devid)
+	t.append("alias", dev.Alias)
+	t.append("group_id", dev.GroupId)
+	t.append("dev_type", dev.DevType)
+	t.append("install_status", dev.InstallStatus)
+	t.append("install_session_key", dev.InstallSessionKey)
+	t.append("install_date", formatTimestamp(dev.InstallDate))
+	t.append("installer", dev.Installer)
+	t.append("location", fmt.Sprintf("%f, %f", dev.Latitude, dev.Longitude))
+	t.append("battery", dev.Battery)
+	t.append("temperature", dev.Temperature)
+	t.append("rssi", dev.Rssi)
+	t.append("app_fw_ver", dev.AppFwVer)
+	t.append("lora_fw_ver", dev.LoraFwVer)
+	t.append("period", dev.Period)
+	t.append("is_alive", dev.IsAlive)
+	t.append("is_alarmed", dev.IsAlarmed)
+	t.append("update_date", formatTimestamp(dev.UpdateDate))
+
+	return e.render(dev, t)
+}
+
+func runDevicesInstall(ctx context.Context, e *env, args []string) error {
+	flags := flag.NewFlagSet("devices install", flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+
+	alias := flags.String("alias", "", "Alias of device")
+	groupID := flags.String("group", "", "Group ID of device")
+	installer := flags.String("installer", "", "Email of installer")
+	latitude := flags.Float64("lat", 0, "Latitude of install location")
+	longitude := flags.Float64("lng", 0, "Longitude of install location")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return ErrMissingArgs
+	}
+
+	cli, err := newDeviceClient()
+	if err != nil {
+		return err
+	}
+
+	resp, err := cli.PrepareInstall(ctx, &pb.PrepareInstallRequest{
+		Devid:     flags.Arg(0),
+		Alias:     *alias,
+		GroupId:   *groupID,
+		Installer: *installer,
+		Latitude:  *latitude,
+		Longitude: *longitude,
+	})
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"DEVID", "RESULT", "INSTALL SESSION"}}
+	t.append(resp.Devid, resp.ResponseCode, resp.InstallSessionKey)
+
+	return e.render(resp, t)
+}
+
+func runDevicesUninstall(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return ErrMissingArgs
+	}
+
+	cli, err := newDeviceClient()
+	if err != nil {
+		return err
+	}
+
+	resp, err := cli.Uninstall(ctx, &pb.UninstallRequest{Devid: args[0]})
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"DEVID", "RESULT"}}
+	t.append(resp.Devid, resp.ResponseCode)
+
+	return e.render(resp, t)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rootwarp/ino-vibe-go-sdk/group"
+)
+
+func runGroupsTree(ctx context.Context, e *env, args []string) error {
+	groupID := ""
+	if len(args) > 0 {
+		groupID = args[0]
+	}
+
+	if err := checkCredentials(); err != nil {
+		return err
+	}
+
+	cli, err := group.NewClient()
+	if err != nil {
+		return err
+	}
+
+	groups, err := cli.List(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"NAME", "ID", "INDIVIDUAL"}}
+	appendGroupTree(t, groups, 0)
+
+	return e.render(groups, t)
+}
+
+func appendGroupTree(t *table, groups []group.Group, depth int) {
+	for _, g := range groups {
+		t.append(strings.Repeat("  ", depth)+g.Name, g.ID, g.Individual)
+		appendGroupTree(t, g.Children, depth+1)
+	}
+}
@@ -0,0 +1,139 @@
+// Command inovibe is command line tool for Ino-Vibe services.
+//
+// Usage:
+//
+//	inovibe [-o table|json] <command> [arguments]
+//
+// Commands:
+//
+//	login                                   Issue access token and store credentials.
+//	token show                              Show claims of stored access token.
+//	devices list|detail|install|uninstall   Manage devices.
+//	groups tree                             Show group tree.
+//	alerts list                             List alerts.
+//	waves list|export                       List or export waves.
+//	parse [-f file] [hex...]                Decode raw LoRa frames from args, file or stdin.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `Usage: inovibe [-o table|json] <command> [arguments]
+
+Commands:
+  login                                   Issue access token and store credentials.
+  token show                              Show claims of stored access token.
+  devices list|detail|install|uninstall   Manage devices.
+  groups tree                             Show group tree.
+  alerts list                             List alerts.
+  waves list|export                       List or export waves.
+  parse [-f file] [hex...]                Decode raw LoRa frames from args, file or stdin.
+`
+
+// Errors
+var (
+	ErrUnknownCommand = errors.New("Unknown command")
+	ErrMissingArgs    = errors.New("Missing arguments")
+)
+
+// env is execution environment of single command.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	output string
+}
+
+type command func(ctx context.Context, e *env, args []string) error
+
+var commands = map[string]map[string]command{
+	"login": {
+		"": runLogin,
+	},
+	"token": {
+		"show": runTokenShow,
+	},
+	"devices": {
+		"list":      runDevicesList,
+		"detail":    runDevicesDetail,
+		"install":   runDevicesInstall,
+		"uninstall": runDevicesUninstall,
+	},
+	"groups": {
+		"tree": runGroupsTree,
+	},
+	"alerts": {
+		"list": runAlertsList,
+	},
+	"waves": {
+		"list":   runWavesList,
+		"export": runWavesExport,
+	},
+	"parse": {
+		"": runParse,
+	},
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("inovibe", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+
+	output := flags.String("o", outputTable, "Output format, table or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *output != outputTable && *output != outputJSON {
+		return fmt.Errorf("Unknown output format %q", *output)
+	}
+
+	args = flags.Args()
+	if len(args) == 0 {
+		flags.Usage()
+		return ErrMissingArgs
+	}
+
+	subs, ok := commands[args[0]]
+	if !ok {
+		flags.Usage()
+		return ErrUnknownCommand
+	}
+
+	cmd, ok := subs[""]
+	args = args[1:]
+	if !ok {
+		if len(args) == 0 {
+			flags.Usage()
+			return ErrMissingArgs
+		}
+
+		cmd, ok = subs[args[0]]
+		if !ok {
+			flags.Usage()
+			return ErrUnknownCommand
+		}
+		args = args[1:]
+	}
+
+	e := &env{stdin: stdin, stdout: stdout, stderr: stderr, output: *output}
+	return cmd(ctx, e, args)
+}
+
+func main() {
+	err := run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if err == flag.ErrHelp {
+		return
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "inovibe:", err)
+		os.Exit(1)
+	}
+}
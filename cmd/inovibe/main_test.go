@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAliveFrame = "0302f411150092100064003bff07ffe3016801044c01000101010c0102060301020223"
+
+func runTest(args ...string) (string, error) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	err := run(context.Background(), args, strings.NewReader(""), stdout, stderr)
+	return stdout.String(), err
+}
+
+func TestCommandUnknown(t *testing.T) {
+	_, err := runTest("unknown")
+	assert.Equal(t, ErrUnknownCommand, err)
+
+	_, err = runTest("devices", "unknown")
+	assert.Equal(t, ErrUnknownCommand, err)
+
+	_, err = runTest("devices")
+	assert.Equal(t, ErrMissingArgs, err)
+
+	_, err = runTest("-o", "yaml", "parse", testAliveFrame)
+	assert.NotNil(t, err)
+}
+
+func TestCommandParseSummary(t *testing.T) {
+	out, err := runTest("parse", testAliveFrame)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "V3 | Dev.: mgi | Seq.: 244 | Bat.: 17 | Temp.: 21 | Err.: 0 | Type: alive, none | RSSI.: -110 | Resv.: 100\n"+
+		"ALIVE | Acc Conf.: 2G, 1100mg | Acc Intr.: 1, 1 | Acc Vals.: 59, -249, -29 | Log: EN(XYZ), 1s, 12blks | Inst.: Y | Per: 360m | App FW: 2.6.3 | LoRa FW: 1.2.2\n", out)
+}
+
+func TestCommandParseJSON(t *testing.T) {
+	out, err := runTest("-o", "json", "parse", testAliveFrame)
+	assert.Nil(t, err)
+
+	decoded := struct {
+		Header struct {
+			Seq  int `json:"seq"`
+			RSSI int `json:"rssi"`
+		} `json:"header"`
+		Payload struct {
+			X int `json:"x"`
+			Y int `json:"y"`
+			Z int `json:"z"`
+		} `json:"payload"`
+	}{}
+
+	assert.Nil(t, json.Unmarshal([]byte(out), &decoded))
+	assert.Equal(t, 244, decoded.Header.Seq)
+	assert.Equal(t, -110, decoded.Header.RSSI)
+	assert.Equal(t, -249, decoded.Payload.Y)
+}
+
+func TestCommandParseStdin(t *testing.T) {
+	input := "RAW | " + testAliveFrame + "\n\n" +
+		"0302e30113009d80000d1c01ffdfffd800e8ffedffbc00e4fff6ffd300d0fff2ffe400e3fff3ffd000effff2ffe200f4ffe7fff400effff8ffee00d3ae\n" +
+		"0302f411150\n"
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	err := run(context.Background(), []string{"-o", "json", "parse"}, strings.NewReader(input), stdout, stderr)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, strings.Count(stdout.String(), "\n"))
+	assert.Contains(t, stderr.String(), "line 4")
+}
+
+func TestCommandParseFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "frames")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	_, _ = f.WriteString(testAliveFrame + "\n")
+	f.Close()
+
+	out, err := runTest("parse", "-f", f.Name())
+
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(out, "V3 | Dev.: mgi | Seq.: 244"))
+}
+
+func TestCommandParseInvalid(t *testing.T) {
+	_, err := runTest("parse", "0302f411150")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+// frame is decoded raw frame.
+type frame struct {
+	Raw     string         `json:"raw"`
+	Header  *parser.Header `json:"header"`
+	Payload interface{}    `json:"payload,omitempty"`
+}
+
+func decodeFrame(raw string) (*frame, error) {
+	p, err := parser.NewFrameParser(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := p.Header()
+	if err != nil {
+		return nil, err
+	}
+
+	f := &frame{Raw: raw, Header: header}
+
+	switch header.Payload.Type {
+	case parser.AliveType:
+		f.Payload, err = p.Alive()
+	case parser.WaveType:
+		f.Payload, err = p.Wave()
+	case parser.NoticeType:
+		f.Payload, err = p.Notice()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Summary returns human readable lines of frame in the format of LoRa network console.
+func (f *frame) Summary() string {
+	lines := f.Header.Summary()
+
+	switch payload := f.Payload.(type) {
+	case *parser.AlivePayload:
+		lines += "\n" + payload.Summary()
+	case *parser.WavePayload:
+		lines += "\n" + payload.Summary()
+	case nil:
+	default:
+		lines += "\n" + parser.NoticeSummary(payload)
+	}
+
+	return lines
+}
+
+// runParse decodes hex frames from arguments, file or stdin, one frame per line.
+// Lines copied from console such as "RAW | 0302..." are accepted.
+func runParse(ctx context.Context, e *env, args []string) error {
+	flags := flag.NewFlagSet("parse", flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+
+	file := flags.String("f", "", "File which contains hex frames, one per line")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var input io.Reader
+	switch {
+	case flags.NArg() > 0:
+		input = strings.NewReader(strings.Join(flags.Args(), "\n"))
+	case *file != "":
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	default:
+		input = e.stdin
+	}
+
+	enc := json.NewEncoder(e.stdout)
+	failed := 0
+	lineNo := 0
+
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		lineNo++
+
+		raw := normalizeHex(scanner.Text())
+		if raw == "" {
+			continue
+		}
+
+		f, err := decodeFrame(raw)
+		if err != nil {
+			e.printf("line %d: %s: %v\n", lineNo, raw, err)
+			failed++
+			continue
+		}
+
+		if e.output == outputJSON {
+			err = enc.Encode(f)
+		} else {
+			_, err = fmt.Fprintln(e.stdout, f.Summary())
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d frames failed to parse", failed)
+	}
+
+	return nil
+}
+
+func normalizeHex(line string) string {
+	line = strings.TrimSpace(line)
+	if idx := strings.LastIndex(line, "|"); idx >= 0 {
+		line = strings.TrimSpace(line[idx+1:])
+	}
+
+	return strings.ToLower(strings.Join(strings.Fields(line), ""))
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"strconv"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+
+	"github.com/rootwarp/ino-vibe-go-sdk/wave"
+)
+
+func newWaveClient() (wave.Client, error) {
+	if err := checkCredentials(); err != nil {
+		return nil, err
+	}
+	return wave.NewClient()
+}
+
+func runWavesList(ctx context.Context, e *env, args []string) error {
+	flags := flag.NewFlagSet("waves list", flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+
+	offset := flags.Int("offset", 0, "Offset of waves")
+	maxCount := flags.Int("max", 10, "Max count of waves")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return ErrMissingArgs
+	}
+
+	cli, err := newWaveClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	waves, err := cli.List(ctx, flags.Arg(0), *offset, *maxCount)
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"WAVE ID", "DEVID", "CREATED", "GROUP", "PREDICTION", "NOTIFY"}}
+	for _, w := range waves {
+		t.append(w.Waveid, w.Devid, formatTimestamp(w.Created), w.GroupName, w.Prediction, w.Notify)
+	}
+
+	return e.render(waves, t)
+}
+
+// runWavesExport writes samples of wave as CSV, or whole wave with -o json.
+func runWavesExport(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return ErrMissingArgs
+	}
+
+	cli, err := newWaveClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	resp, err := cli.Detail(ctx, &pb.WaveDetailRequest{Waveid: args[0]})
+	if err != nil {
+		return err
+	}
+
+	if resp.ResponseCode != pb.ResponseCode_SUCCESS || resp.Wave == nil {
+		return fmt.Errorf("Wave %s does not exist", args[0])
+	}
+
+	if e.output == outputJSON {
+		return e.render(resp.Wave, nil)
+	}
+
+	return writeWaveCSV(e, resp.Wave)
+}
+
+func writeWaveCSV(e *env, w *pb.WaveDetailItem) error {
+	samples := len(w.X)
+	if len(w.Y) > samples {
+		samples = len(w.Y)
+	}
+	if len(w.Z) > samples {
+		samples = len(w.Z)
+	}
+
+	sample := func(values []int32, i int) string {
+		if i < len(values) {
+			return strconv.Itoa(int(values[i]))
+		}
+		return ""
+	}
+
+	cw := csv.NewWriter(e.stdout)
+	_ = cw.Write([]string{"index", "x", "y", "z"})
+	for i := 0; i < samples; i++ {
+		_ = cw.Write([]string{strconv.Itoa(i), sample(w.X, i), sample(w.Y, i), sample(w.Z, i)})
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
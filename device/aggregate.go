@@ -0,0 +1,125 @@
+package device
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Aggregation intervals.
+const (
+	Hourly = time.Hour
+	Daily  = 24 * time.Hour
+)
+
+// statusLogPageSize is number of status logs requested at once while aggregating.
+var statusLogPageSize = 500
+
+// Stat is summary of values in a bucket.
+type Stat struct {
+	Min  int
+	Max  int
+	Avg  float64
+	Last int
+}
+
+func (s *Stat) add(value int, n int) {
+	if n == 1 {
+		*s = Stat{Min: value, Max: value, Avg: float64(value), Last: value}
+		return
+	}
+
+	if value < s.Min {
+		s.Min = value
+	}
+	if value > s.Max {
+		s.Max = value
+	}
+	s.Avg += (float64(value) - s.Avg) / float64(n)
+}
+
+// StatusBucket is aggregate of status logs within [Start, Start+interval).
+type StatusBucket struct {
+	Start       time.Time
+	Count       int
+	Battery     Stat
+	Temperature Stat
+	RSSI        Stat
+}
+
+// AggregateStatusLog returns status of install session aggregated into buckets of interval.
+// Buckets are aligned to interval in UTC, sorted by time and empty buckets are omitted.
+// Zero timeFrom and timeTo cover whole install session until now.
+func AggregateStatusLog(ctx context.Context, cli Client, devid, installSession string, timeFrom, timeTo time.Time, interval time.Duration) ([]StatusBucket, error) {
+	if interval <= 0 {
+		return []StatusBucket{}, ErrInvalidParameter
+	}
+
+	if timeFrom.IsZero() {
+		timeFrom = time.Unix(0, 0)
+	}
+	if timeTo.IsZero() {
+		timeTo = time.Now()
+	}
+
+	buckets := map[int64]*StatusBucket{}
+
+	err := eachStatusLog(ctx, cli, devid, installSession, timeFrom, timeTo, func(l StatusLog) {
+		start := l.Time.UTC().Truncate(interval)
+
+		bucket, ok := buckets[start.UnixNano()]
+		if !ok {
+			bucket = &StatusBucket{Start: start}
+			buckets[start.UnixNano()] = bucket
+		}
+
+		// Logs arrive latest first, so first log of bucket is the last value.
+		bucket.Count++
+		bucket.Battery.add(l.Battery, bucket.Count)
+		bucket.Temperature.add(l.Temperature, bucket.Count)
+		bucket.RSSI.add(l.RSSI, bucket.Count)
+	})
+	if err != nil {
+		return []StatusBucket{}, err
+	}
+
+	series := make([]StatusBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		series = append(series, *bucket)
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Start.Before(series[j].Start)
+	})
+
+	return series, nil
+}
+
+// eachStatusLog pages through status logs of time range, latest first.
+// Each page ends at time of last log of previous page instead of offset from the latest,
+// so logs stored while paging do not shift pages. Offset only skips logs of that same time.
+func eachStatusLog(ctx context.Context, cli Client, devid, installSession string, timeFrom, timeTo time.Time, fn func(StatusLog)) error {
+	for offset := 0; ; {
+		logs, err := cli.StatusLog(ctx, devid, installSession, timeFrom, timeTo, offset, statusLogPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, l := range logs {
+			fn(l)
+		}
+
+		if len(logs) < statusLogPageSize {
+			return nil
+		}
+
+		last := logs[len(logs)-1].Time
+		if !last.Equal(timeTo) {
+			timeTo, offset = last, 0
+		}
+
+		for i := len(logs) - 1; i >= 0 && logs[i].Time.Equal(last); i-- {
+			offset++
+		}
+	}
+}
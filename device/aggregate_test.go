@@ -0,0 +1,108 @@
+package device
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+func TestAggregateStatusLog(t *testing.T) {
+	cli, _, store := newTestClient(t, &pb.Device{Devid: "dev-1", InstallSessionKey: "session-1"})
+	ctx := context.Background()
+
+	pageSize := statusLogPageSize
+	statusLogPageSize = 2
+	defer func() { statusLogPageSize = pageSize }()
+
+	base := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+	logs := []StatusLog{
+		{Time: base.Add(5 * time.Minute), Battery: 90, Temperature: 20, RSSI: -80},
+		{Time: base.Add(20 * time.Minute), Battery: 89, Temperature: 24, RSSI: -70},
+		{Time: base.Add(50 * time.Minute), Battery: 88, Temperature: 22, RSSI: -90},
+		{Time: base.Add(2*time.Hour + time.Minute), Battery: 80, Temperature: 30, RSSI: -60},
+		{Time: base.Add(26 * time.Hour), Battery: 70, Temperature: 10, RSSI: -100},
+	}
+	for _, l := range logs {
+		l.Devid = "dev-1"
+		l.InstallSessionKey = "session-1"
+		_ = store.PutStatusLog(ctx, &l)
+	}
+	_ = store.PutStatusLog(ctx, &StatusLog{Devid: "dev-1", InstallSessionKey: "session-0", Time: base, Battery: 100})
+
+	hourly, err := AggregateStatusLog(ctx, cli, "dev-1", "session-1", time.Time{}, time.Time{}, Hourly)
+	assert.Nil(t, err)
+	assert.Len(t, hourly, 3)
+
+	assert.Equal(t, StatusBucket{
+		Start:       base,
+		Count:       3,
+		Battery:     Stat{Min: 88, Max: 90, Avg: 89, Last: 88},
+		Temperature: Stat{Min: 20, Max: 24, Avg: 22, Last: 22},
+		RSSI:        Stat{Min: -90, Max: -70, Avg: -80, Last: -90},
+	}, hourly[0])
+	assert.Equal(t, base.Add(2*time.Hour), hourly[1].Start)
+	assert.Equal(t, 1, hourly[1].Count)
+	assert.Equal(t, Stat{Min: 80, Max: 80, Avg: 80, Last: 80}, hourly[1].Battery)
+
+	daily, err := AggregateStatusLog(ctx, cli, "dev-1", "session-1", time.Time{}, time.Time{}, Daily)
+	assert.Nil(t, err)
+	assert.Len(t, daily, 2)
+	assert.Equal(t, time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC), daily[0].Start)
+	assert.Equal(t, 4, daily[0].Count)
+	assert.Equal(t, 80, daily[0].Battery.Last)
+	assert.Equal(t, 1, daily[1].Count)
+
+	ranged, err := AggregateStatusLog(ctx, cli, "dev-1", "session-1", base, base.Add(time.Hour), Hourly)
+	assert.Nil(t, err)
+	assert.Len(t, ranged, 1)
+
+	_, err = AggregateStatusLog(ctx, cli, "dev-1", "session-1", time.Time{}, time.Time{}, 0)
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, err = AggregateStatusLog(ctx, cli, "unknown", "session-1", time.Time{}, time.Time{}, Hourly)
+	assert.Equal(t, ErrNonExistDevice, err)
+}
+
+// growingLogClient stores newer status log whenever a page of status logs is read.
+type growingLogClient struct {
+	Client
+	store LogStore
+	next  time.Time
+}
+
+func (c *growingLogClient) StatusLog(ctx context.Context, devid, installSession string, timeFrom, timeTo time.Time, offset, limit int) ([]StatusLog, error) {
+	logs, err := c.Client.StatusLog(ctx, devid, installSession, timeFrom, timeTo, offset, limit)
+
+	c.next = c.next.Add(time.Minute)
+	_ = c.store.PutStatusLog(ctx, &StatusLog{Devid: devid, InstallSessionKey: installSession, Time: c.next, Battery: 1})
+
+	return logs, err
+}
+
+func TestAggregateStatusLogPaging(t *testing.T) {
+	cli, _, store := newTestClient(t, &pb.Device{Devid: "dev-1", InstallSessionKey: "session-1"})
+	ctx := context.Background()
+
+	pageSize := statusLogPageSize
+	statusLogPageSize = 2
+	defer func() { statusLogPageSize = pageSize }()
+
+	// Logs of same time span pages.
+	base := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+	times := []time.Time{base, base.Add(time.Minute), base.Add(time.Minute), base.Add(time.Minute), base.Add(2 * time.Minute)}
+	for i, at := range times {
+		_ = store.PutStatusLog(ctx, &StatusLog{Devid: "dev-1", InstallSessionKey: "session-1", Time: at, Battery: 90 - i})
+	}
+
+	growing := &growingLogClient{Client: cli, store: store, next: base.Add(time.Hour)}
+
+	daily, err := AggregateStatusLog(ctx, growing, "dev-1", "session-1", base, base.Add(Daily), Daily)
+	assert.Nil(t, err)
+	assert.Len(t, daily, 1)
+	assert.Equal(t, len(times), daily[0].Count)
+	assert.Equal(t, Stat{Min: 86, Max: 90, Avg: 88, Last: 86}, daily[0].Battery)
+}
@@ -0,0 +1,153 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+const defaultBatchConcurrency = 8
+
+// ErrUpdateFailed describes server did not apply update request.
+var ErrUpdateFailed = errors.New("Update request is failed")
+
+// BatchOptions controls concurrency of batch request.
+type BatchOptions struct {
+	// Concurrency is maximum number of requests in flight. Default is 8.
+	Concurrency int
+	// RatePerSecond limits number of requests started in a second. Zero means no limit.
+	RatePerSecond float64
+}
+
+// BatchResult is result of a request of batch.
+type BatchResult struct {
+	Devid    string
+	Response *pb.DeviceResponse
+	Err      error
+}
+
+// BatchResults is results of batch in same order to requests.
+type BatchResults []BatchResult
+
+// Failed returns results which have error.
+func (r BatchResults) Failed() BatchResults {
+	failed := make(BatchResults, 0)
+	for _, result := range r {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err returns first error of results or nil if every request is succeeded.
+func (r BatchResults) Err() error {
+	for _, result := range r {
+		if result.Err != nil {
+			return result.Err
+		}
+	}
+	return nil
+}
+
+// BatchUpdateInfo updates information of devices.
+func (c *client) BatchUpdateInfo(ctx context.Context, reqs []*pb.DeviceInfoUpdateRequest, opts BatchOptions) BatchResults {
+	results := make(BatchResults, len(reqs))
+	runBatch(ctx, len(reqs), opts, func(ctx context.Context, i int) {
+		resp, err := c.UpdateInfo(ctx, reqs[i])
+		results[i] = newBatchResult(reqs[i].Devid, resp, err)
+	}, func(i int, err error) {
+		results[i] = BatchResult{Devid: reqs[i].Devid, Err: err}
+	})
+
+	return results
+}
+
+// BatchUpdateStatus updates status of devices.
+func (c *client) BatchUpdateStatus(ctx context.Context, reqs []*pb.DeviceStatusUpdateRequest, opts BatchOptions) BatchResults {
+	results := make(BatchResults, len(reqs))
+	runBatch(ctx, len(reqs), opts, func(ctx context.Context, i int) {
+		resp, err := c.UpdateStatus(ctx, reqs[i])
+		results[i] = newBatchResult(reqs[i].Devid, resp, err)
+	}, func(i int, err error) {
+		results[i] = BatchResult{Devid: reqs[i].Devid, Err: err}
+	})
+
+	return results
+}
+
+// BatchUpdateConfig updates configs of devices.
+func (c *client) BatchUpdateConfig(ctx context.Context, reqs []*pb.DeviceConfigUpdateRequest, opts BatchOptions) BatchResults {
+	results := make(BatchResults, len(reqs))
+	runBatch(ctx, len(reqs), opts, func(ctx context.Context, i int) {
+		resp, err := c.UpdateConfig(ctx, reqs[i])
+		results[i] = newBatchResult(reqs[i].Devid, resp, err)
+	}, func(i int, err error) {
+		results[i] = BatchResult{Devid: reqs[i].Devid, Err: err}
+	})
+
+	return results
+}
+
+func newBatchResult(devid string, resp *pb.DeviceResponse, err error) BatchResult {
+	if err == nil {
+		err = responseError(resp.ResultCode, ErrUpdateFailed)
+	}
+
+	return BatchResult{Devid: devid, Response: resp, Err: err}
+}
+
+// runBatch calls do for each index with bounded concurrency and rate.
+// skip is called for requests which are not started because context is done.
+func runBatch(ctx context.Context, n int, opts BatchOptions, do func(context.Context, int), skip func(int, error)) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	var tick <-chan time.Time
+	if opts.RatePerSecond > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.RatePerSecond))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < n; i++ {
+		if tick != nil && i > 0 {
+			select {
+			case <-ctx.Done():
+			case <-tick:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+
+		if ctx.Err() != nil {
+			for ; i < n; i++ {
+				skip(i, ctx.Err())
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			do(ctx, i)
+		}(i)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,162 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/fake/faketest"
+)
+
+// batchDevices returns count devices of group-1.
+func batchDevices(count int) []*pb.Device {
+	devs := make([]*pb.Device, count)
+	for i := range devs {
+		devs[i] = &pb.Device{Devid: fmt.Sprintf("dev-%d", i), GroupId: "group-1"}
+	}
+	return devs
+}
+
+func TestBatchUpdateInfo(t *testing.T) {
+	cli, srv, _ := newTestClient(t, batchDevices(20)...)
+
+	reqs := make([]*pb.DeviceInfoUpdateRequest, 0)
+	for i := 0; i < 20; i++ {
+		reqs = append(reqs, &pb.DeviceInfoUpdateRequest{
+			Devid:   fmt.Sprintf("dev-%d", i),
+			GroupId: &pb.DeviceInfoUpdateRequest_GroupIdValue{GroupIdValue: "group-2"},
+		})
+	}
+	reqs = append(reqs, &pb.DeviceInfoUpdateRequest{Devid: "unknown"})
+
+	results := cli.BatchUpdateInfo(context.Background(), reqs, BatchOptions{Concurrency: 4})
+
+	assert.Len(t, results, 21)
+	for i, result := range results[:20] {
+		assert.Equal(t, fmt.Sprintf("dev-%d", i), result.Devid)
+		assert.Nil(t, result.Err)
+		assert.Equal(t, "group-2", srv.Device(result.Devid).GroupId)
+	}
+
+	assert.Equal(t, BatchResults{{Devid: "unknown", Response: results[20].Response, Err: ErrNonExistDevice}}, results.Failed())
+	assert.Equal(t, ErrNonExistDevice, results.Err())
+}
+
+// TestBatchLazyConnect runs batch on client which connects on first request.
+// Run with -race to check lazy connection.
+func TestBatchLazyConnect(t *testing.T) {
+	srv, conn := faketest.NewServer(t)
+	for _, dev := range batchDevices(20) {
+		srv.AddDevice(dev)
+	}
+
+	var dials int32
+	dial := dialDeviceService
+	dialDeviceService = func(*oauth2.Token) pb.DeviceServiceClient {
+		atomic.AddInt32(&dials, 1)
+		time.Sleep(10 * time.Millisecond)
+		return pb.NewDeviceServiceClient(conn)
+	}
+	defer func() { dialDeviceService = dial }()
+
+	cli := &client{oauthToken: &oauth2.Token{AccessToken: "test-token"}}
+
+	reqs := make([]*pb.DeviceStatusUpdateRequest, 20)
+	for i := range reqs {
+		reqs[i] = &pb.DeviceStatusUpdateRequest{
+			Devid:   fmt.Sprintf("dev-%d", i),
+			Battery: &pb.DeviceStatusUpdateRequest_BatteryValue{BatteryValue: 50},
+		}
+	}
+
+	results := cli.BatchUpdateStatus(context.Background(), reqs, BatchOptions{Concurrency: 8})
+
+	assert.Nil(t, results.Err())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&dials))
+	assert.Equal(t, uint32(50), srv.Device("dev-19").Battery)
+}
+
+func TestBatchUpdateConfigAndStatus(t *testing.T) {
+	cli, srv, _ := newTestClient(t, batchDevices(3)...)
+	ctx := context.Background()
+
+	configResults := cli.BatchUpdateConfig(ctx, []*pb.DeviceConfigUpdateRequest{
+		{Devid: "dev-0", WaveBlocks: &pb.DeviceConfigUpdateRequest_WaveBlocksValue{WaveBlocksValue: 4}},
+		{Devid: "dev-1", WaveBlocks: &pb.DeviceConfigUpdateRequest_WaveBlocksValue{WaveBlocksValue: 8}},
+	}, BatchOptions{})
+
+	assert.Nil(t, configResults.Err())
+	assert.Equal(t, uint32(4), srv.Device("dev-0").WaveBlocks)
+	assert.Equal(t, uint32(8), srv.Device("dev-1").WaveBlocks)
+
+	statusResults := cli.BatchUpdateStatus(ctx, []*pb.DeviceStatusUpdateRequest{
+		{Devid: "dev-2", Battery: &pb.DeviceStatusUpdateRequest_BatteryValue{BatteryValue: 50}},
+	}, BatchOptions{})
+
+	assert.Nil(t, statusResults.Err())
+	assert.Equal(t, uint32(50), srv.Device("dev-2").Battery)
+}
+
+func TestBatchRateLimit(t *testing.T) {
+	cli, _, _ := newTestClient(t, batchDevices(5)...)
+
+	reqs := make([]*pb.DeviceStatusUpdateRequest, 5)
+	for i := range reqs {
+		reqs[i] = &pb.DeviceStatusUpdateRequest{Devid: fmt.Sprintf("dev-%d", i)}
+	}
+
+	start := time.Now()
+	results := cli.BatchUpdateStatus(context.Background(), reqs, BatchOptions{RatePerSecond: 100})
+
+	assert.Nil(t, results.Err())
+	assert.True(t, time.Since(start) >= 40*time.Millisecond)
+}
+
+func TestBatchCanceled(t *testing.T) {
+	cli, _, _ := newTestClient(t, batchDevices(5)...)
+
+	reqs := make([]*pb.DeviceStatusUpdateRequest, 5)
+	for i := range reqs {
+		reqs[i] = &pb.DeviceStatusUpdateRequest{Devid: fmt.Sprintf("dev-%d", i)}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := cli.BatchUpdateStatus(ctx, reqs, BatchOptions{})
+
+	assert.Len(t, results, 5)
+	for i, result := range results {
+		assert.Equal(t, fmt.Sprintf("dev-%d", i), result.Devid)
+		assert.Equal(t, context.Canceled, result.Err)
+	}
+}
+
+func TestRunBatchConcurrency(t *testing.T) {
+	mu := sync.Mutex{}
+	running, maxRunning := 0, 0
+
+	runBatch(context.Background(), 20, BatchOptions{Concurrency: 3}, func(ctx context.Context, i int) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}, func(int, error) {})
+
+	assert.Equal(t, 3, maxRunning)
+}
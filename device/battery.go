@@ -0,0 +1,184 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"google.golang.org/api/iterator"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+const minBatterySamples = 3
+
+// ErrInsufficientHistory describes status history is too short to predict battery.
+var ErrInsufficientHistory = errors.New("Not enough status logs to predict battery")
+
+// BatteryPrediction is estimated discharge of battery in an install session.
+type BatteryPrediction struct {
+	Devid             string
+	InstallSessionKey string
+	// Battery is latest reported level in percent.
+	Battery int
+	// RatePerDay is discharge in percent per day, and zero if battery is not discharging.
+	RatePerDay float64
+	// DaysRemaining is +Inf if battery is not discharging.
+	DaysRemaining   float64
+	ReplacementDate time.Time
+	Samples         int
+}
+
+// AlivePeriod returns reporting period of alive payload.
+func AlivePeriod(alive *parser.AlivePayload) time.Duration {
+	return time.Duration(alive.AlivePeriod) * time.Minute
+}
+
+// PredictBattery fits discharge of battery over current install session of device.
+// Discharge is regarded as cost of reports at period of device and projected with alivePeriod,
+// so change of period is reflected. Missed reports and gaps of history do not change discharge rate.
+// Period of device is used if alivePeriod is zero.
+//
+// ErrNonExistDevice returns if device does not exist.
+// ErrInsufficientHistory returns if install session has too few status logs.
+func PredictBattery(ctx context.Context, cli Client, devid string, alivePeriod time.Duration) (*BatteryPrediction, error) {
+	resp, err := cli.Detail(ctx, devid)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.ResultCode != pb.ResponseCode_SUCCESS {
+		return nil, ErrNonExistDevice
+	}
+
+	return predictDeviceBattery(ctx, cli, resp.Devices[0], alivePeriod, time.Now())
+}
+
+// BatteryReport returns predictions of devices which are expected to run out within days, soonest first.
+// Devices without enough history are skipped.
+func BatteryReport(ctx context.Context, cli Client, filter Filter, days float64) ([]BatteryPrediction, error) {
+	it := cli.FilterListIter(ctx, filter.Request())
+	defer it.Close()
+
+	now := time.Now()
+	report := make([]BatteryPrediction, 0)
+
+	for {
+		dev, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+
+		if err != nil {
+			return report, err
+		}
+
+		if dev.InstallStatus != pb.InstallStatus_Installed {
+			continue
+		}
+
+		prediction, err := predictDeviceBattery(ctx, cli, dev, 0, now)
+		if err == ErrInsufficientHistory {
+			continue
+		}
+
+		if err != nil {
+			return report, err
+		}
+
+		if prediction.DaysRemaining <= days {
+			report = append(report, *prediction)
+		}
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		return report[i].DaysRemaining < report[j].DaysRemaining
+	})
+
+	return report, nil
+}
+
+func predictDeviceBattery(ctx context.Context, cli Client, dev *pb.Device, alivePeriod time.Duration, now time.Time) (*BatteryPrediction, error) {
+	period := time.Duration(dev.Period) * time.Minute
+	if alivePeriod == 0 {
+		alivePeriod = period
+	}
+
+	logs := make([]StatusLog, 0)
+	err := eachStatusLog(ctx, cli, dev.Devid, dev.InstallSessionKey, time.Unix(0, 0), now, func(l StatusLog) {
+		logs = append(logs, l)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prediction, err := predictBattery(logs, period, alivePeriod, now)
+	if err != nil {
+		return nil, err
+	}
+
+	prediction.Devid = dev.Devid
+	prediction.InstallSessionKey = dev.InstallSessionKey
+
+	return prediction, nil
+}
+
+// predictBattery fits battery level linearly over time by least squares.
+// Rate is scaled by reports per day of alivePeriod to those of period which logs were reported at.
+func predictBattery(logs []StatusLog, period, alivePeriod time.Duration, now time.Time) (*BatteryPrediction, error) {
+	if len(logs) < minBatterySamples {
+		return nil, ErrInsufficientHistory
+	}
+
+	sorted := make([]StatusLog, len(logs))
+	copy(sorted, logs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	first, last := sorted[0], sorted[len(sorted)-1]
+	span := last.Time.Sub(first.Time)
+	if span <= 0 {
+		return nil, ErrInsufficientHistory
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for _, l := range sorted {
+		x := l.Time.Sub(first.Time).Hours()
+		y := float64(l.Battery)
+
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	n := float64(len(sorted))
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+
+	prediction := &BatteryPrediction{
+		Battery:       last.Battery,
+		DaysRemaining: math.Inf(1),
+		Samples:       len(sorted),
+	}
+
+	if slope >= 0 {
+		return prediction, nil
+	}
+
+	perDay := -slope * 24
+	if period > 0 && alivePeriod > 0 {
+		perDay *= float64(period) / float64(alivePeriod)
+	}
+
+	daysLeft := float64(last.Battery) / perDay
+
+	prediction.RatePerDay = perDay
+	prediction.ReplacementDate = last.Time.Add(time.Duration(daysLeft * float64(24*time.Hour)))
+	prediction.DaysRemaining = prediction.ReplacementDate.Sub(now).Hours() / 24
+
+	return prediction, nil
+}
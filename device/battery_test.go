@@ -0,0 +1,135 @@
+package device
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+// batteryLogs returns hourly logs which discharge perDay percent a day.
+func batteryLogs(devid, session string, start time.Time, hours int, battery, perDay float64) []StatusLog {
+	logs := make([]StatusLog, hours)
+	for i := range logs {
+		logs[i] = StatusLog{
+			Devid:             devid,
+			InstallSessionKey: session,
+			Time:              start.Add(time.Duration(i) * time.Hour),
+			Battery:           int(math.Round(battery - perDay*float64(i)/24)),
+		}
+	}
+	return logs
+}
+
+func TestPredictBattery(t *testing.T) {
+	start := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	logs := batteryLogs("dev-1", "s", start, 24*10+1, 100, 2)
+	now := start.Add(10 * 24 * time.Hour)
+
+	prediction, err := predictBattery(logs, time.Hour, 0, now)
+	assert.Nil(t, err)
+	assert.Equal(t, 80, prediction.Battery)
+	assert.InDelta(t, 2, prediction.RatePerDay, 0.01)
+	assert.InDelta(t, 40, prediction.DaysRemaining, 0.5)
+	assert.WithinDuration(t, now.Add(40*24*time.Hour), prediction.ReplacementDate, 12*time.Hour)
+	assert.Equal(t, 241, prediction.Samples)
+
+	// Same history with reports twice as often drains twice as fast.
+	prediction, err = predictBattery(logs, time.Hour, 30*time.Minute, now)
+	assert.Nil(t, err)
+	assert.InDelta(t, 4, prediction.RatePerDay, 0.02)
+	assert.InDelta(t, 20, prediction.DaysRemaining, 0.5)
+}
+
+func TestPredictBatteryMissedReports(t *testing.T) {
+	start := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(10 * 24 * time.Hour)
+
+	// Every third alive and whole 5th day are missing.
+	logs := make([]StatusLog, 0)
+	for i, l := range batteryLogs("dev-1", "s", start, 24*10+1, 100, 2) {
+		if i%3 == 1 || (i >= 24*4 && i < 24*5) {
+			continue
+		}
+		logs = append(logs, l)
+	}
+
+	prediction, err := predictBattery(logs, time.Hour, time.Hour, now)
+	assert.Nil(t, err)
+	assert.InDelta(t, 2, prediction.RatePerDay, 0.02)
+	assert.InDelta(t, 40, prediction.DaysRemaining, 0.5)
+}
+
+func TestPredictBatteryNotDischarging(t *testing.T) {
+	start := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	logs := batteryLogs("dev-1", "s", start, 5, 90, 0)
+
+	prediction, err := predictBattery(logs, time.Hour, time.Hour, start)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(0), prediction.RatePerDay)
+	assert.True(t, math.IsInf(prediction.DaysRemaining, 1))
+	assert.True(t, prediction.ReplacementDate.IsZero())
+}
+
+func TestPredictBatteryInsufficient(t *testing.T) {
+	start := time.Now()
+
+	_, err := predictBattery(batteryLogs("dev-1", "s", start, 2, 90, 10), time.Hour, 0, start)
+	assert.Equal(t, ErrInsufficientHistory, err)
+
+	sameTime := []StatusLog{{Time: start, Battery: 90}, {Time: start, Battery: 80}, {Time: start, Battery: 70}}
+	_, err = predictBattery(sameTime, time.Hour, 0, start)
+	assert.Equal(t, ErrInsufficientHistory, err)
+}
+
+func TestAlivePeriod(t *testing.T) {
+	assert.Equal(t, 6*time.Hour, AlivePeriod(&parser.AlivePayload{AlivePeriod: 360}))
+}
+
+func TestBatteryReport(t *testing.T) {
+	cli, _, store := newTestClient(t,
+		&pb.Device{Devid: "fast", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s1", Period: 60},
+		&pb.Device{Devid: "slow", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s2", Period: 60},
+		&pb.Device{Devid: "new", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s3", Period: 60},
+		&pb.Device{Devid: "initial", InstallStatus: pb.InstallStatus_Initial},
+	)
+	ctx := context.Background()
+
+	start := time.Now().Add(-48 * time.Hour).Truncate(time.Hour)
+	for _, l := range batteryLogs("fast", "s1", start, 48, 30, 5) {
+		l := l
+		_ = store.PutStatusLog(ctx, &l)
+	}
+	for _, l := range batteryLogs("slow", "s2", start, 48, 90, 1) {
+		l := l
+		_ = store.PutStatusLog(ctx, &l)
+	}
+	_ = store.PutStatusLog(ctx, &StatusLog{Devid: "new", InstallSessionKey: "s3", Time: start, Battery: 100})
+
+	report, err := BatteryReport(ctx, cli, Filter{}, 30)
+	assert.Nil(t, err)
+	assert.Len(t, report, 1)
+	assert.Equal(t, "fast", report[0].Devid)
+	assert.Equal(t, "s1", report[0].InstallSessionKey)
+	assert.InDelta(t, 4, report[0].DaysRemaining, 0.5)
+
+	report, err = BatteryReport(ctx, cli, Filter{}, 365)
+	assert.Nil(t, err)
+	assert.Len(t, report, 2)
+	assert.Equal(t, "slow", report[1].Devid)
+
+	prediction, err := PredictBattery(ctx, cli, "slow", 0)
+	assert.Nil(t, err)
+	assert.InDelta(t, 1, prediction.RatePerDay, 0.2)
+
+	_, err = PredictBattery(ctx, cli, "new", 0)
+	assert.Equal(t, ErrInsufficientHistory, err)
+
+	_, err = PredictBattery(ctx, cli, "unknown", 0)
+	assert.Equal(t, ErrNonExistDevice, err)
+}
@@ -0,0 +1,23 @@
+package device
+
+import (
+	"testing"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/fake"
+	"github.com/rootwarp/ino-vibe-go-sdk/fake/faketest"
+)
+
+// newTestClient creates client connected to fake server which has devs.
+// Logs are kept in memory store.
+func newTestClient(t *testing.T, devs ...*pb.Device) (Client, *fake.Server, LogStore) {
+	srv, conn := faketest.NewServer(t)
+	for _, dev := range devs {
+		srv.AddDevice(dev)
+	}
+
+	store := NewMemoryLogStore()
+	cli, _ := NewClient(WithConn(conn), WithLogStore(store))
+
+	return cli, srv, store
+}
@@ -0,0 +1,326 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+// Limits of config values.
+const (
+	maxThresholdMg       = 16000
+	minAlivePeriod       = time.Minute
+	maxAlivePeriod       = 24 * time.Hour
+	maxLogBlocks         = 255
+	maxInclinationPeriod = 24 * 60
+)
+
+// Config is application configuration of device.
+// Fields mirror parser.ApplicationConfig and log settings of parser.AlivePayload.
+// DeviceService keeps only some of them, see Unsupported.
+type Config struct {
+	AppMode parser.ApplicationMode
+	Range   parser.ConfigBMARange
+
+	HighGThresholdMg  int
+	RejectThresholdMg int
+	ImpactThresholdMg int
+	// InclinationCheckPeriod is period of inclination check in minutes, for AppModeExaInc.
+	InclinationCheckPeriod int
+
+	// MRMT thresholds are used for AppModeMRMT only.
+	MRMTOperationThresholdMg int
+	MRMTShockThresholdMg     int
+
+	AlivePeriod time.Duration
+	LogEnable   bool
+	LogInterval time.Duration
+	LogBlocks   int
+}
+
+// ConfigError describes invalid field of config.
+type ConfigError struct {
+	Field  string
+	Value  interface{}
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("Invalid config %s=%v: %s", e.Field, e.Value, e.Reason)
+}
+
+// Unwrap makes errors.Is match ErrInvalidParameter.
+func (e *ConfigError) Unwrap() error {
+	return ErrInvalidParameter
+}
+
+// ConfigDiff is field which has different values.
+type ConfigDiff struct {
+	Field    string
+	Desired  interface{}
+	Reported interface{}
+}
+
+func (d ConfigDiff) String() string {
+	return fmt.Sprintf("%s: %v != %v", d.Field, d.Desired, d.Reported)
+}
+
+// Validate checks ranges of values and fields which are required by application mode.
+// ConfigError returns for the first invalid field.
+func (c Config) Validate() error {
+	invalid := func(field string, value interface{}, reason string) error {
+		return &ConfigError{Field: field, Value: value, Reason: reason}
+	}
+
+	switch c.AppMode {
+	case parser.AppModeExaInc, parser.AppModeMRMT, parser.AppModeImpact:
+	default:
+		return invalid("AppMode", c.AppMode, "unknown mode")
+	}
+
+	fullScaleMg := c.Range.Gravity() * 1000
+	if fullScaleMg == 0 {
+		return invalid("Range", c.Range, "unknown range")
+	}
+
+	if c.HighGThresholdMg <= 0 || c.HighGThresholdMg > fullScaleMg {
+		return invalid("HighGThresholdMg", c.HighGThresholdMg, fmt.Sprintf("should be in (0, %d]", fullScaleMg))
+	}
+
+	thresholds := []struct {
+		field string
+		value int
+	}{
+		{"RejectThresholdMg", c.RejectThresholdMg},
+		{"ImpactThresholdMg", c.ImpactThresholdMg},
+		{"MRMTOperationThresholdMg", c.MRMTOperationThresholdMg},
+		{"MRMTShockThresholdMg", c.MRMTShockThresholdMg},
+	}
+	for _, threshold := range thresholds {
+		if threshold.value < 0 || threshold.value > maxThresholdMg {
+			return invalid(threshold.field, threshold.value, fmt.Sprintf("should be in [0, %d]", maxThresholdMg))
+		}
+	}
+
+	switch c.AppMode {
+	case parser.AppModeExaInc:
+		if c.InclinationCheckPeriod <= 0 || c.InclinationCheckPeriod > maxInclinationPeriod {
+			return invalid("InclinationCheckPeriod", c.InclinationCheckPeriod, fmt.Sprintf("should be in (0, %d] for inclination mode", maxInclinationPeriod))
+		}
+	case parser.AppModeImpact:
+		if c.ImpactThresholdMg == 0 {
+			return invalid("ImpactThresholdMg", c.ImpactThresholdMg, "required for impact mode")
+		}
+
+		if c.RejectThresholdMg >= c.ImpactThresholdMg {
+			return invalid("RejectThresholdMg", c.RejectThresholdMg, "should be less than ImpactThresholdMg")
+		}
+	case parser.AppModeMRMT:
+		if c.MRMTOperationThresholdMg == 0 {
+			return invalid("MRMTOperationThresholdMg", c.MRMTOperationThresholdMg, "required for machine runtime mode")
+		}
+
+		if c.MRMTShockThresholdMg <= c.MRMTOperationThresholdMg {
+			return invalid("MRMTShockThresholdMg", c.MRMTShockThresholdMg, "should be greater than MRMTOperationThresholdMg")
+		}
+	}
+
+	if c.AppMode != parser.AppModeMRMT && (c.MRMTOperationThresholdMg != 0 || c.MRMTShockThresholdMg != 0) {
+		return invalid("AppMode", c.AppMode, "MRMT thresholds are for machine runtime mode only")
+	}
+
+	if c.AlivePeriod < minAlivePeriod || c.AlivePeriod > maxAlivePeriod || c.AlivePeriod%time.Minute != 0 {
+		return invalid("AlivePeriod", c.AlivePeriod, fmt.Sprintf("should be whole minutes in [%s, %s]", minAlivePeriod, maxAlivePeriod))
+	}
+
+	if c.LogBlocks < 0 || c.LogBlocks > maxLogBlocks {
+		return invalid("LogBlocks", c.LogBlocks, fmt.Sprintf("should be in [0, %d]", maxLogBlocks))
+	}
+
+	if c.LogEnable && (c.LogInterval < time.Second || c.LogInterval%time.Second != 0) {
+		return invalid("LogInterval", c.LogInterval, "should be whole seconds if log is enabled")
+	}
+
+	return nil
+}
+
+// appliedFields returns fields which ConfigRequest and InfoRequest send.
+// ImpactThresholdMg is sent as decision threshold in impact mode only.
+func (c Config) appliedFields() []string {
+	fields := []string{"Range", "HighGThresholdMg", "AlivePeriod", "LogBlocks"}
+	if c.AppMode == parser.AppModeImpact {
+		fields = append(fields, "ImpactThresholdMg")
+	}
+
+	return fields
+}
+
+// Unsupported returns fields which DeviceService can not keep, in order of declaration.
+// ApplyConfig does not apply them, so they should be configured on device otherwise.
+// AppMode is always returned and other fields are returned if they have values.
+func (c Config) Unsupported() []string {
+	unsupported := make([]string, 0)
+
+	value := reflect.ValueOf(c)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i).Name
+		if containsString(c.appliedFields(), field) {
+			continue
+		}
+
+		if field == "AppMode" || !value.Field(i).IsZero() {
+			unsupported = append(unsupported, field)
+		}
+	}
+
+	return unsupported
+}
+
+// ConfigRequest returns request of values which DeviceService keeps in config.
+// Values which Unsupported returns are not in request.
+func (c Config) ConfigRequest(devid string) (*pb.DeviceConfigUpdateRequest, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	req := &pb.DeviceConfigUpdateRequest{
+		Devid:        devid,
+		SensorRange:  &pb.DeviceConfigUpdateRequest_SensorRangeValue{SensorRangeValue: pb.SensorRangeType(c.Range)},
+		IntThreshold: &pb.DeviceConfigUpdateRequest_IntThresholdValue{IntThresholdValue: float64(c.HighGThresholdMg)},
+		WaveBlocks:   &pb.DeviceConfigUpdateRequest_WaveBlocksValue{WaveBlocksValue: uint32(c.LogBlocks)},
+	}
+
+	if c.AppMode == parser.AppModeImpact {
+		req.DecisionThreshold = &pb.DeviceConfigUpdateRequest_DecisionThresholdValue{DecisionThresholdValue: float64(c.ImpactThresholdMg)}
+	}
+
+	return req, nil
+}
+
+// InfoRequest returns request of alive period which DeviceService keeps in info.
+func (c Config) InfoRequest(devid string) (*pb.DeviceInfoUpdateRequest, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	req := &pb.DeviceInfoUpdateRequest{
+		Devid:  devid,
+		Period: &pb.DeviceInfoUpdateRequest_PeriodValue{PeriodValue: uint32(c.AlivePeriod / time.Minute)},
+	}
+
+	return req, nil
+}
+
+// ApplyConfig validates config and updates config and alive period of device.
+// Values which Unsupported returns are not applied.
+//
+// ConfigError returns if config is invalid.
+// ErrNonExistDevice returns if device does not exist.
+func ApplyConfig(ctx context.Context, cli Client, devid string, cfg Config) error {
+	configReq, err := cfg.ConfigRequest(devid)
+	if err != nil {
+		return err
+	}
+
+	infoReq, err := cfg.InfoRequest(devid)
+	if err != nil {
+		return err
+	}
+
+	resp, err := cli.UpdateConfig(ctx, configReq)
+	if err != nil {
+		return err
+	}
+
+	if err := responseError(resp.ResultCode, ErrUpdateFailed); err != nil {
+		return err
+	}
+
+	resp, err = cli.UpdateInfo(ctx, infoReq)
+	if err != nil {
+		return err
+	}
+
+	return responseError(resp.ResultCode, ErrUpdateFailed)
+}
+
+// Stored returns c with values which DeviceService keeps for device.
+func (c Config) Stored(dev *pb.Device) Config {
+	c.Range = parser.ConfigBMARange(dev.SensorRange)
+	c.HighGThresholdMg = int(dev.IntThresholdMg)
+	c.ImpactThresholdMg = int(dev.DecisionThresholdMg)
+	c.LogBlocks = int(dev.WaveBlocks)
+	c.AlivePeriod = time.Duration(dev.Period) * time.Minute
+
+	return c
+}
+
+// Reported returns c with values which device reported.
+// Values of nil payload are kept.
+func (c Config) Reported(appConfig *parser.ApplicationConfig, alive *parser.AlivePayload) Config {
+	if alive != nil {
+		c.Range = parser.ConfigBMARange(alive.Sensitivity)
+		c.AlivePeriod = AlivePeriod(alive)
+		c.LogEnable = alive.LogEnable != 0
+		c.LogInterval = time.Duration(alive.LogInterval) * time.Second
+		c.LogBlocks = int(alive.LogBlocks)
+	}
+
+	if appConfig != nil {
+		c.AppMode = appConfig.AppMode
+		c.Range = appConfig.BMARange
+		c.HighGThresholdMg = appConfig.BMAHighGThresholdMg
+		c.RejectThresholdMg = appConfig.NRFRejectThresholdMg
+		c.ImpactThresholdMg = appConfig.NRFImpactThresholdMg
+		c.InclinationCheckPeriod = appConfig.InclinationCheckPeriod
+		c.MRMTOperationThresholdMg = appConfig.MRMTOperationThresholdMg
+		c.MRMTShockThresholdMg = appConfig.MRMTShockThresholdMg
+	}
+
+	return c
+}
+
+// filterDiffs returns diffs of fields.
+func filterDiffs(diffs []ConfigDiff, fields []string) []ConfigDiff {
+	filtered := make([]ConfigDiff, 0)
+	for _, diff := range diffs {
+		if containsString(fields, diff.Field) {
+			filtered = append(filtered, diff)
+		}
+	}
+
+	return filtered
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Diff returns fields which have different values in reported, in order of declaration.
+func (c Config) Diff(reported Config) []ConfigDiff {
+	diffs := make([]ConfigDiff, 0)
+
+	desiredValue, reportedValue := reflect.ValueOf(c), reflect.ValueOf(reported)
+	for i := 0; i < desiredValue.NumField(); i++ {
+		desired, got := desiredValue.Field(i).Interface(), reportedValue.Field(i).Interface()
+		if desired != got {
+			diffs = append(diffs, ConfigDiff{
+				Field:    desiredValue.Type().Field(i).Name,
+				Desired:  desired,
+				Reported: got,
+			})
+		}
+	}
+
+	return diffs
+}
@@ -0,0 +1,164 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+func testImpactConfig() Config {
+	return Config{
+		AppMode:           parser.AppModeImpact,
+		Range:             parser.ConfigBMARange16G,
+		HighGThresholdMg:  1900,
+		RejectThresholdMg: 12,
+		ImpactThresholdMg: 320,
+		AlivePeriod:       6 * time.Hour,
+		LogEnable:         true,
+		LogInterval:       time.Second,
+		LogBlocks:         12,
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.Nil(t, testImpactConfig().Validate())
+
+	mrmt := testImpactConfig()
+	mrmt.AppMode = parser.AppModeMRMT
+	mrmt.MRMTOperationThresholdMg = 100
+	mrmt.MRMTShockThresholdMg = 3000
+	assert.Nil(t, mrmt.Validate())
+
+	tests := []struct {
+		Desc   string
+		Modify func(*Config)
+		Field  string
+	}{
+		{"Unknown mode", func(c *Config) { c.AppMode = 9 }, "AppMode"},
+		{"Unknown range", func(c *Config) { c.Range = 0 }, "Range"},
+		{"High G over range", func(c *Config) { c.HighGThresholdMg = 16001 }, "HighGThresholdMg"},
+		{"Negative threshold", func(c *Config) { c.RejectThresholdMg = -1 }, "RejectThresholdMg"},
+		{"Impact without threshold", func(c *Config) { c.ImpactThresholdMg = 0 }, "ImpactThresholdMg"},
+		{"Reject over impact", func(c *Config) { c.RejectThresholdMg = 400 }, "RejectThresholdMg"},
+		{"MRMT on impact mode", func(c *Config) { c.MRMTOperationThresholdMg = 100 }, "AppMode"},
+		{"MRMT without threshold", func(c *Config) { c.AppMode = parser.AppModeMRMT }, "MRMTOperationThresholdMg"},
+		{"Inclination without period", func(c *Config) { c.AppMode = parser.AppModeExaInc }, "InclinationCheckPeriod"},
+		{"Alive period in seconds", func(c *Config) { c.AlivePeriod = 90 * time.Second }, "AlivePeriod"},
+		{"Too many log blocks", func(c *Config) { c.LogBlocks = 256 }, "LogBlocks"},
+		{"Log without interval", func(c *Config) { c.LogInterval = 0 }, "LogInterval"},
+	}
+
+	for _, test := range tests {
+		cfg := testImpactConfig()
+		test.Modify(&cfg)
+
+		err := cfg.Validate()
+		assert.True(t, errors.Is(err, ErrInvalidParameter), test.Desc)
+
+		var cfgErr *ConfigError
+		if assert.True(t, errors.As(err, &cfgErr), test.Desc) {
+			assert.Equal(t, test.Field, cfgErr.Field, test.Desc)
+		}
+	}
+}
+
+func TestConfigRequest(t *testing.T) {
+	cfg := testImpactConfig()
+
+	req, err := cfg.ConfigRequest("dev-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "dev-1", req.Devid)
+	assert.Equal(t, pb.SensorRangeType_Gravity16, req.GetSensorRangeValue())
+	assert.Equal(t, float64(1900), req.GetIntThresholdValue())
+	assert.Equal(t, float64(320), req.GetDecisionThresholdValue())
+	assert.Equal(t, uint32(12), req.GetWaveBlocksValue())
+
+	info, err := cfg.InfoRequest("dev-1")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(360), info.GetPeriodValue())
+
+	// Decision threshold is for impact mode only.
+	runtime := MachineRuntimeTemplate.Config
+	req, err = runtime.ConfigRequest("dev-1")
+	assert.Nil(t, err)
+	assert.Nil(t, req.DecisionThreshold)
+	assert.Equal(t, float64(4000), req.GetIntThresholdValue())
+
+	cfg.Range = 0
+	_, err = cfg.ConfigRequest("dev-1")
+	assert.True(t, errors.Is(err, ErrInvalidParameter))
+}
+
+func TestConfigUnsupported(t *testing.T) {
+	assert.Equal(t, []string{"AppMode", "RejectThresholdMg", "LogEnable", "LogInterval"}, testImpactConfig().Unsupported())
+
+	assert.Equal(t, []string{"AppMode", "MRMTOperationThresholdMg", "MRMTShockThresholdMg", "LogEnable", "LogInterval"},
+		MachineRuntimeTemplate.Config.Unsupported())
+
+	cfg := BridgeInclinationTemplate.Config
+	assert.Equal(t, []string{"AppMode", "InclinationCheckPeriod"}, cfg.Unsupported())
+
+	cfg.ImpactThresholdMg = 320
+	assert.Equal(t, []string{"AppMode", "ImpactThresholdMg", "InclinationCheckPeriod"}, cfg.Unsupported())
+}
+
+func TestApplyConfig(t *testing.T) {
+	cli, _, _ := newTestClient(t, &pb.Device{Devid: "dev-1"})
+	ctx := context.Background()
+
+	cfg := testImpactConfig()
+	assert.Nil(t, ApplyConfig(ctx, cli, "dev-1", cfg))
+
+	resp, _ := cli.Detail(ctx, "dev-1")
+	stored := Config{}.Stored(resp.Devices[0])
+	assert.Equal(t, cfg.Range, stored.Range)
+	assert.Equal(t, cfg.HighGThresholdMg, stored.HighGThresholdMg)
+	assert.Equal(t, cfg.ImpactThresholdMg, stored.ImpactThresholdMg)
+	assert.Equal(t, cfg.LogBlocks, stored.LogBlocks)
+	assert.Equal(t, cfg.AlivePeriod, stored.AlivePeriod)
+
+	// Decision threshold is kept by config of other modes.
+	assert.Nil(t, ApplyConfig(ctx, cli, "dev-1", MachineRuntimeTemplate.Config))
+	resp, _ = cli.Detail(ctx, "dev-1")
+	assert.Equal(t, float64(320), resp.Devices[0].DecisionThresholdMg)
+	assert.Equal(t, float64(4000), resp.Devices[0].IntThresholdMg)
+
+	assert.Equal(t, ErrNonExistDevice, ApplyConfig(ctx, cli, "unknown", cfg))
+
+	cfg.AlivePeriod = 0
+	assert.True(t, errors.Is(ApplyConfig(ctx, cli, "dev-1", cfg), ErrInvalidParameter))
+}
+
+func TestConfigDiff(t *testing.T) {
+	desired := testImpactConfig()
+
+	frame, _ := parser.NewFrameParser(testConfigFrame)
+	_, _ = frame.Header()
+	notice, _ := frame.Notice()
+	appConfig := notice.(parser.ApplicationConfig)
+
+	frame, _ = parser.NewFrameParser(testAliveFrame)
+	_, _ = frame.Header()
+	alive, _ := frame.Alive()
+
+	assert.Empty(t, desired.Diff(desired.Reported(&appConfig, nil)))
+	assert.Empty(t, desired.Diff(desired.Reported(nil, nil)))
+
+	// Alive reports 2G range which application config overrides.
+	assert.Empty(t, desired.Diff(desired.Reported(&appConfig, alive)))
+
+	desired.ImpactThresholdMg = 500
+	desired.AlivePeriod = time.Hour
+	diffs := desired.Diff(desired.Reported(&appConfig, alive))
+	assert.Equal(t, []ConfigDiff{
+		{Field: "ImpactThresholdMg", Desired: 500, Reported: 320},
+		{Field: "AlivePeriod", Desired: time.Hour, Reported: 6 * time.Hour},
+	}, diffs)
+	assert.Equal(t, "AlivePeriod: 1h0m0s != 6h0m0s", diffs[1].String())
+}
@@ -4,9 +4,8 @@ import (
 	"context"
 	"crypto/x509"
 	"errors"
-	"io"
 	"log"
-	"math"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/datastore"
@@ -19,11 +18,7 @@ import (
 
 	pb "bitbucket.org/ino-on/ino-vibe-api"
 	iv_auth "github.com/rootwarp/ino-vibe-go-sdk/auth"
-)
-
-const (
-	statusLogKind   = "DevStatusLog"
-	inclinationKind = "inclination-log"
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
 )
 
 var (
@@ -44,6 +39,8 @@ type Client interface {
 	// Deprecated:
 	List(context.Context, pb.InstallStatus) (*pb.DeviceListResponse, error)
 	FilterList(context.Context, *pb.DeviceFilterListRequest) ([]*pb.Device, error)
+	FilterListIter(context.Context, *pb.DeviceFilterListRequest) *DeviceIterator
+	Watch(ctx context.Context, filter Filter, interval time.Duration) <-chan WatchEvent
 
 	Detail(context.Context, string) (*pb.DeviceResponse, error)
 
@@ -51,11 +48,20 @@ type Client interface {
 	UpdateStatus(context.Context, *pb.DeviceStatusUpdateRequest) (*pb.DeviceResponse, error)
 	UpdateConfig(context.Context, *pb.DeviceConfigUpdateRequest) (*pb.DeviceResponse, error)
 
+	BatchUpdateInfo(context.Context, []*pb.DeviceInfoUpdateRequest, BatchOptions) BatchResults
+	BatchUpdateStatus(context.Context, []*pb.DeviceStatusUpdateRequest, BatchOptions) BatchResults
+	BatchUpdateConfig(context.Context, []*pb.DeviceConfigUpdateRequest, BatchOptions) BatchResults
+
 	StatusLog(ctx context.Context, devid, installKey string, timeFrom, timeTo time.Time, offset, limit int) ([]StatusLog, error)
 	StoreStatusLog(ctx context.Context, devid string, battery, temperature, RSSI int) error
 
+	InclinationLogs(ctx context.Context, devid, installKey string, timeFrom, timeTo time.Time, offset, limit int) ([]InclinationLog, error)
 	LastInclinationLog(context.Context, string) (*InclinationLog, error)
 	StoreInclinationLog(context.Context, string, int, int, int) (float64, error)
+	StoreCalibratedInclinationLog(ctx context.Context, devid string, rawX, rawY, rawZ int, baseline Acceleration) (*InclinationLog, error)
+
+	Ingest(ctx context.Context, devid, rawHex string) (*IngestResult, error)
+	PacketStats(ctx context.Context, devid string, timeFrom, timeTo time.Time) (*PacketStats, error)
 
 	PrepareInstall(context.Context, *pb.PrepareInstallRequest) (*pb.PrepareInstallResponse, error)
 	CompleteInstall(context.Context, *pb.CompleteInstallRequest) (*pb.CompleteInstallResponse, error)
@@ -68,49 +74,69 @@ type Client interface {
 type client struct {
 	oauthToken   *oauth2.Token
 	deviceClient pb.DeviceServiceClient
-	dsClient     *datastore.Client
+	logStore     LogStore
+
+	// initMu guards lazy connection of deviceClient and logStore.
+	initMu sync.Mutex
+
+	ingestMu  sync.Mutex
+	sequences *SequenceTracker
+	baselines map[string]Acceleration
+}
+
+// dialDeviceService connects to DeviceService with token.
+var dialDeviceService = func(token *oauth2.Token) pb.DeviceServiceClient {
+	certPool, err := x509.SystemCertPool()
+	if err != nil {
+		log.Panicln(err)
+	}
+
+	creds := credentials.NewClientTLSFromCert(certPool, "")
+	conn, _ := grpc.Dial(
+		serverURL,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithPerRPCCredentials(oauth.NewOauthAccess(token)),
+	)
+
+	return pb.NewDeviceServiceClient(conn)
 }
 
 func (c *client) getDeviceClient() pb.DeviceServiceClient {
+	c.initMu.Lock()
+	defer c.initMu.Unlock()
+
 	if c.deviceClient == nil {
 		if c.oauthToken == nil {
 			log.Panicln(errors.New("No credentials"))
 		}
 
-		certPool, err := x509.SystemCertPool()
-		if err != nil {
-			log.Panicln(err)
-		}
-
-		creds := credentials.NewClientTLSFromCert(certPool, "")
-		conn, _ := grpc.Dial(
-			serverURL,
-			grpc.WithTransportCredentials(creds),
-			grpc.WithPerRPCCredentials(oauth.NewOauthAccess(c.oauthToken)),
-		)
-		c.deviceClient = pb.NewDeviceServiceClient(conn)
+		c.deviceClient = dialDeviceService(c.oauthToken)
 	}
 
 	return c.deviceClient
 }
 
-func (c *client) getDatastoreClient() *datastore.Client {
-	if c.dsClient == nil {
-		var err error
+// getLogStore returns configured LogStore.
+// Datastore of default Google Cloud project is used if LogStore is not configured.
+func (c *client) getLogStore(ctx context.Context) (LogStore, error) {
+	c.initMu.Lock()
+	defer c.initMu.Unlock()
 
-		ctx := context.Background()
+	if c.logStore == nil {
 		cred, err := google.FindDefaultCredentials(ctx)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 
-		c.dsClient, err = datastore.NewClient(ctx, cred.ProjectID)
+		dsClient, err := datastore.NewClient(ctx, cred.ProjectID)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
+
+		c.logStore = NewDatastoreLogStore(dsClient)
 	}
 
-	return c.dsClient
+	return c.logStore, nil
 }
 
 // Deprecated: List returns slice of devices.
@@ -126,28 +152,25 @@ func (c *client) List(ctx context.Context, installStatus pb.InstallStatus) (*pb.
 }
 
 // FilterList returns device of filter constraints.
+// Devices received before stream fails are returned with the error.
 func (c *client) FilterList(ctx context.Context, f *pb.DeviceFilterListRequest) ([]*pb.Device, error) {
-	cli := c.getDeviceClient()
-
-	client, err := cli.FilterList(ctx, f)
+	it := c.FilterListIter(ctx, f)
+	defer it.Close()
 
 	retDevs := make([]*pb.Device, 0)
 
 	for {
-		dev, err := client.Recv()
-		if err == io.EOF {
-			err = nil
-			break
+		dev, err := it.Next()
+		if err == iterator.Done {
+			return retDevs, nil
 		}
 
 		if err != nil {
-			return []*pb.Device{}, err
+			return retDevs, err
 		}
 
 		retDevs = append(retDevs, dev)
 	}
-
-	return retDevs, err
 }
 
 // Detail returns detail information of selected device.
@@ -196,40 +219,12 @@ func (c *client) StatusLog(ctx context.Context, devid, installSession string, ti
 		return []StatusLog{}, err
 	}
 
-	dsCli := c.getDatastoreClient()
-
-	q := datastore.NewQuery(statusLogKind).
-		Filter("Devid =", devid).
-		Filter("InstallSessionKey =", installSession).
-		Filter("Time >=", timeFrom).
-		Filter("Time <=", timeTo).
-		Order("-Time").
-		Offset(offset).
-		Limit(limit)
-
-	iter := dsCli.Run(ctx, q)
-
-	logs := make([]StatusLog, 0, limit)
-	idx := 0
-
-	for {
-		newLog := StatusLog{}
-		_, err := iter.Next(&newLog)
-		if err == iterator.Done {
-			break
-		}
-
-		if err, ok := err.(*datastore.ErrFieldMismatch); ok {
-			log.Println("StatusLog", err)
-		} else if err != nil {
-			return []StatusLog{}, err
-		}
-
-		logs = append(logs, newLog)
-		idx++
+	store, err := c.getLogStore(ctx)
+	if err != nil {
+		return []StatusLog{}, err
 	}
 
-	return logs, nil
+	return store.StatusLogs(ctx, devid, installSession, timeFrom, timeTo, offset, limit)
 }
 
 func (c *client) getDevice(ctx context.Context, devid string) (*pb.Device, error) {
@@ -249,6 +244,20 @@ func (c *client) getDevice(ctx context.Context, devid string) (*pb.Device, error
 	return device, nil
 }
 
+// responseError converts response code into error.
+// failed returns on codes which has no matching error.
+func responseError(code pb.ResponseCode, failed error) error {
+	switch code {
+	case pb.ResponseCode_SUCCESS:
+		return nil
+	case pb.ResponseCode_NON_EXIST:
+		return ErrNonExistDevice
+	case pb.ResponseCode_NOT_ALLOWED:
+		return ErrForbiddenInstallStatus
+	}
+	return failed
+}
+
 // StoreStatusLog stores requested values into StatusLog entity.
 // New status log can be stored onto installed device or return ErrForbeddenInstallStatus error.
 //
@@ -260,24 +269,55 @@ func (c *client) StoreStatusLog(ctx context.Context, devid string, battery, temp
 		return err
 	}
 
+	_, err = c.putStatusLog(ctx, device, StatusLog{Battery: battery, Temperature: temperature, RSSI: RSSI})
+	return err
+}
+
+// putStatusLog stores newLog on current install session of device.
+func (c *client) putStatusLog(ctx context.Context, device *pb.Device, newLog StatusLog) (*StatusLog, error) {
 	if device.InstallStatus != pb.InstallStatus_Installed {
-		return ErrForbiddenInstallStatus
+		return nil, ErrForbiddenInstallStatus
 	}
 
-	newLog := StatusLog{
-		Devid:             devid,
-		Time:              time.Now(),
-		Temperature:       temperature,
-		Battery:           battery,
-		RSSI:              RSSI,
-		InstallSessionKey: device.InstallSessionKey,
+	newLog.Devid = device.Devid
+	newLog.Time = time.Now()
+	newLog.InstallSessionKey = device.InstallSessionKey
+
+	store, err := c.getLogStore(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	dsCli := c.getDatastoreClient()
-	newKey := datastore.IncompleteKey(statusLogKind, nil)
-	_, err = dsCli.Put(ctx, newKey, &newLog)
+	if err := store.PutStatusLog(ctx, &newLog); err != nil {
+		return nil, err
+	}
 
-	return err
+	return &newLog, nil
+}
+
+// InclinationLogs returns slice of inclination log of selected install session within time range.
+// ErrNonExistDevice
+// ErrInvalidParameter
+func (c *client) InclinationLogs(ctx context.Context, devid, installSession string, timeFrom, timeTo time.Time, offset, limit int) ([]InclinationLog, error) {
+	if timeFrom.After(timeTo) {
+		return []InclinationLog{}, ErrInvalidParameter
+	}
+
+	if offset < 0 || limit <= 0 {
+		return []InclinationLog{}, ErrInvalidParameter
+	}
+
+	_, err := c.getDevice(ctx, devid)
+	if err != nil {
+		return []InclinationLog{}, err
+	}
+
+	store, err := c.getLogStore(ctx)
+	if err != nil {
+		return []InclinationLog{}, err
+	}
+
+	return store.InclinationLogs(ctx, devid, installSession, timeFrom, timeTo, offset, limit)
 }
 
 // LastInclinationLog try to get latest inclination log of selected device.
@@ -291,29 +331,25 @@ func (c *client) LastInclinationLog(ctx context.Context, devid string) (*Inclina
 		return nil, err
 	}
 
-	dsCli := c.getDatastoreClient()
-	q := datastore.NewQuery(inclinationKind).
-		Filter("devid =", devid).
-		Order("-time_created").
-		Limit(1)
+	store, err := c.getLogStore(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	iter := dsCli.Run(ctx, q)
+	latestInclination, err := store.LastInclinationLog(ctx, devid)
+	if err != nil {
+		return nil, err
+	}
 
-	latestInclination := InclinationLog{}
-	_, err = iter.Next(&latestInclination)
-	switch {
-	case err == nil && latestInclination.InstallSessionKey != device.InstallSessionKey:
+	if latestInclination.InstallSessionKey != device.InstallSessionKey {
 		return nil, ErrNoEntities
-	case err == iterator.Done:
-		return nil, ErrNoEntities
-	case err != nil:
-		return nil, err
 	}
 
-	return &latestInclination, nil
+	return latestInclination, nil
 }
 
-// StoreInclinationLog creates new inclination log.
+// StoreInclinationLog creates new inclination log relative to baseline of latest application config
+// as Ingest does. DefaultBaseline is used if device has not reported it.
 // Return calculated angle in degree unit.
 //
 // ErrNonExistDevice returns if requested device is not exist.
@@ -325,83 +361,189 @@ func (c *client) StoreInclinationLog(ctx context.Context, devid string, rawX, ra
 		return 0, err
 	}
 
-	if device.InstallStatus != pb.InstallStatus_Installed {
-		return 0, ErrForbiddenInstallStatus
+	baseline, err := c.baseline(ctx, devid)
+	if err != nil {
+		return 0, err
+	}
+
+	newLog, err := c.putInclinationLog(ctx, device, rawX, rawY, rawZ, baseline)
+	if err != nil {
+		return 0, err
 	}
 
-	var unit float64
-	if device.DevType == pb.DeviceType_InoVibe {
-		unit = 3.9
-	} else {
-		unit = 0.244
+	return newLog.AngleZ, nil
+}
+
+// StoreCalibratedInclinationLog creates new inclination log with tilt relative to baseline.
+// Baseline is usually taken from application config by ConfigBaseline.
+//
+// ErrNonExistDevice returns if requested device is not exist.
+// ErrForbiddenInstallStatus returns if requested device is not installed.
+// ErrInvalidInclinationValue returns if acceleration is zero, NaN or Inf.
+func (c *client) StoreCalibratedInclinationLog(ctx context.Context, devid string, rawX, rawY, rawZ int, baseline Acceleration) (*InclinationLog, error) {
+	device, err := c.getDevice(ctx, devid)
+	if err != nil {
+		return nil, err
 	}
 
-	x, y, z := float64(rawX)*unit, float64(rawY)*unit, float64(rawZ)*unit
-	angleZ := angle(x, y, z, unit)
+	return c.putInclinationLog(ctx, device, rawX, rawY, rawZ, baseline)
+}
 
-	if math.IsNaN(angleZ) || math.IsInf(angleZ, 0) {
-		return 0, ErrInvalidInclinationValue
+func (c *client) putInclinationLog(ctx context.Context, device *pb.Device, rawX, rawY, rawZ int, baseline Acceleration) (*InclinationLog, error) {
+	if device.InstallStatus != pb.InstallStatus_Installed {
+		return nil, ErrForbiddenInstallStatus
+	}
+
+	unit := resolution(device)
+	acc := Acceleration{X: float64(rawX) * unit, Y: float64(rawY) * unit, Z: float64(rawZ) * unit}
+	tilt, err := ComputeTilt(acc, baseline)
+	if err != nil {
+		return nil, err
 	}
 
 	newLog := InclinationLog{
-		Devid:             devid,
+		Devid:             device.Devid,
 		Time:              time.Now(),
 		InstallSessionKey: device.InstallSessionKey,
-		AccXMg:            x,
-		AccYMg:            y,
-		AccZMg:            z,
-		AngleZ:            angleZ,
+		AccXMg:            acc.X,
+		AccYMg:            acc.Y,
+		AccZMg:            acc.Z,
+		AngleZ:            angle(acc.X, acc.Y, acc.Z),
+		Pitch:             tilt.Pitch,
+		Roll:              tilt.Roll,
+		Tilt:              tilt.Total,
+	}
+
+	store, err := c.getLogStore(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	dsCli := c.getDatastoreClient()
-	newKey := datastore.IncompleteKey(inclinationKind, nil)
-	_, err = dsCli.Put(ctx, newKey, &newLog)
+	if err := store.PutInclinationLog(ctx, &newLog); err != nil {
+		return nil, err
+	}
 
-	return angleZ, err
+	return &newLog, nil
 }
 
-func angle(x, y, z, unit float64) float64 {
-	t := z / math.Sqrt(math.Pow(x, 2)+math.Pow(y, 2))
-	rad := math.Atan(t)
-	return rad * (180 / math.Pi)
+// resolution returns accelerometer resolution of device in mg/LSB.
+// Unknown device type is regarded as InoVibeS.
+func resolution(device *pb.Device) float64 {
+	hw, ok := parser.LookupProtoHardware(device.DevType)
+	if !ok {
+		hw, _ = parser.LookupHardware(parser.InoVibeS)
+	}
+
+	return hw.Resolution
+}
+
+// validateInstallRequest checks request is permitted on current install status of device.
+//
+// ErrNonExistDevice returns if requested device is not exist.
+// InstallStatusError returns if request is not permitted.
+func (c *client) validateInstallRequest(ctx context.Context, devid string, req InstallRequest) error {
+	device, err := c.getDevice(ctx, devid)
+	if err != nil {
+		return err
+	}
+
+	if _, err := NextInstallStatus(device.InstallStatus, req); err != nil {
+		if statusErr, ok := err.(*InstallStatusError); ok {
+			statusErr.Devid = devid
+		}
+		return err
+	}
+
+	return nil
 }
 
 func (c *client) PrepareInstall(ctx context.Context, in *pb.PrepareInstallRequest) (*pb.PrepareInstallResponse, error) {
+	if err := c.validateInstallRequest(ctx, in.Devid, RequestPrepareInstall); err != nil {
+		return nil, err
+	}
+
 	cli := c.getDeviceClient()
 	return cli.PrepareInstall(ctx, in)
 }
 
 func (c *client) CompleteInstall(ctx context.Context, in *pb.CompleteInstallRequest) (*pb.CompleteInstallResponse, error) {
+	if err := c.validateInstallRequest(ctx, in.Devid, RequestCompleteInstall); err != nil {
+		return nil, err
+	}
+
 	cli := c.getDeviceClient()
 	return cli.CompleteInstall(ctx, in)
 }
 
 func (c *client) Uninstalling(ctx context.Context, in *pb.UninstallingRequest) (*pb.UninstallingResponse, error) {
+	if err := c.validateInstallRequest(ctx, in.Devid, RequestUninstalling); err != nil {
+		return nil, err
+	}
+
 	cli := c.getDeviceClient()
 	return cli.Uninstalling(ctx, in)
 }
 
 func (c *client) Uninstall(ctx context.Context, in *pb.UninstallRequest) (*pb.UninstallResponse, error) {
+	if err := c.validateInstallRequest(ctx, in.Devid, RequestUninstall); err != nil {
+		return nil, err
+	}
+
 	cli := c.getDeviceClient()
 	return cli.Uninstall(ctx, in)
 }
 
 func (c *client) Discard(ctx context.Context, in *pb.DiscardRequest) (*pb.DiscardResponse, error) {
+	if err := c.validateInstallRequest(ctx, in.Devid, RequestDiscard); err != nil {
+		return nil, err
+	}
+
 	cli := c.getDeviceClient()
 	return cli.Discard(ctx, in)
 }
 
 func (c *client) WaitCompleteInstall(ctx context.Context, in *pb.WaitCompleteInstallRequest) (*pb.WaitCompleteInstallResponse, error) {
+	if err := c.validateInstallRequest(ctx, in.Devid, RequestWaitCompleteInstall); err != nil {
+		return nil, err
+	}
+
 	cli := c.getDeviceClient()
 	return cli.WaitCompleteInstall(ctx, in)
 }
 
+// Option configures client.
+type Option func(*client)
+
+// WithConn makes client use established connection instead of dialing Ino-Vibe server.
+// Credentials are not loaded and connection is not closed by client.
+func WithConn(conn grpc.ClientConnInterface) Option {
+	return func(c *client) {
+		c.deviceClient = pb.NewDeviceServiceClient(conn)
+	}
+}
+
+// WithLogStore makes client keep status and inclination logs in store instead of Datastore.
+func WithLogStore(store LogStore) Option {
+	return func(c *client) {
+		c.logStore = store
+	}
+}
+
 // NewClient create client.
-func NewClient() (Client, error) {
-	token, err := iv_auth.LoadCredentials()
-	if err != nil {
-		log.Panicln(err)
+func NewClient(opts ...Option) (Client, error) {
+	c := &client{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.deviceClient == nil {
+		token, err := iv_auth.LoadCredentials()
+		if err != nil {
+			log.Panicln(err)
+		}
+
+		c.oauthToken = token
 	}
 
-	return &client{oauthToken: token}, nil
+	return c, nil
 }
@@ -1,3 +1,6 @@
+//go:build live
+// +build live
+
 package device
 
 import (
@@ -912,46 +915,3 @@ func TestStoreInclination(t *testing.T) {
 		}
 	}
 }
-
-func TestAngle(t *testing.T) {
-	tests := []struct {
-		X float64
-		Y float64
-		Z float64
-	}{
-		{
-			X: 0.0,
-			Y: 0.0,
-			Z: 998.0,
-		},
-
-		{
-			X: 993.812,
-			Y: -13.908,
-			Z: -1.22,
-		},
-
-		{
-			X: 993.812,
-			Y: -13.908,
-			Z: 1.22,
-		},
-
-		{
-			X: 13.908,
-			Y: 993.812,
-			Z: 1.22,
-		},
-		{
-			X: -13.908,
-			Y: 993.812,
-			Z: 1.22,
-		},
-	}
-
-	for i, test := range tests {
-		angleZ := angle(test.X, test.Y, test.Z, 1)
-		fmt.Println("Angle", i, angleZ)
-	}
-
-}
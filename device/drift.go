@@ -0,0 +1,180 @@
+package device
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// DriftPoint is inclination relative to first reading of install session.
+type DriftPoint struct {
+	Time time.Time
+	// Angle is angle of Z axis from horizontal plane in degree.
+	Angle float64
+	// Tilt is orientation relative to baseline.
+	Tilt Tilt
+	// Drift is angle between orientation and baseline in degree, which is Tilt.Total.
+	Drift float64
+	// Rate is angle between orientation and previous reading in degree per hour.
+	Rate float64
+}
+
+// InclinationTrend is inclination history of an install session.
+type InclinationTrend struct {
+	Devid             string
+	InstallSessionKey string
+	// Baseline is first reading after install is completed.
+	Baseline InclinationLog
+	Points   []DriftPoint
+}
+
+// DriftThreshold configures when drift produces alert.
+// Zero value disables the limit.
+type DriftThreshold struct {
+	// MaxDrift is absolute drift from baseline in degree.
+	MaxDrift float64
+	// MaxRate is absolute change rate in degree per hour.
+	MaxRate float64
+}
+
+// DriftAlertType is reason of drift alert.
+type DriftAlertType int
+
+// Drift alert types.
+const (
+	DriftExceeded DriftAlertType = iota + 1
+	RateExceeded
+)
+
+func (t DriftAlertType) String() string {
+	switch t {
+	case DriftExceeded:
+		return "DriftExceeded"
+	case RateExceeded:
+		return "RateExceeded"
+	}
+	return "Unknown"
+}
+
+// DriftAlert is raised when inclination crosses threshold.
+type DriftAlert struct {
+	Type  DriftAlertType
+	Devid string
+	Point DriftPoint
+}
+
+// InclinationHistory returns all inclination logs of install session, oldest first.
+func InclinationHistory(ctx context.Context, cli Client, devid, installSession string) ([]InclinationLog, error) {
+	logs := make([]InclinationLog, 0)
+
+	for offset := 0; ; {
+		page, err := cli.InclinationLogs(ctx, devid, installSession, time.Unix(0, 0), time.Now(), offset, statusLogPageSize)
+		if err != nil {
+			return []InclinationLog{}, err
+		}
+
+		logs = append(logs, page...)
+
+		if len(page) < statusLogPageSize {
+			break
+		}
+
+		offset += len(page)
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].Time.Before(logs[j].Time)
+	})
+
+	return logs, nil
+}
+
+// Trend returns drift of install session relative to its first inclination log.
+// Logs without acceleration are skipped.
+// ErrNoEntities returns if install session has no inclination log.
+func Trend(ctx context.Context, cli Client, devid, installSession string) (*InclinationTrend, error) {
+	logs, err := InclinationHistory(ctx, cli, devid, installSession)
+	if err != nil {
+		return nil, err
+	}
+
+	logs = accelerationLogs(logs)
+	if len(logs) == 0 {
+		return nil, ErrNoEntities
+	}
+
+	trend := &InclinationTrend{
+		Devid:             devid,
+		InstallSessionKey: installSession,
+		Baseline:          logs[0],
+		Points:            driftPoints(logs),
+	}
+
+	return trend, nil
+}
+
+func (l *InclinationLog) acceleration() Acceleration {
+	return Acceleration{X: l.AccXMg, Y: l.AccYMg, Z: l.AccZMg}
+}
+
+// accelerationLogs returns logs which have valid acceleration.
+func accelerationLogs(logs []InclinationLog) []InclinationLog {
+	valid := make([]InclinationLog, 0, len(logs))
+	for _, l := range logs {
+		if l.acceleration().valid() {
+			valid = append(valid, l)
+		}
+	}
+	return valid
+}
+
+// driftPoints returns tilt of logs relative to first log.
+// Every log should have valid acceleration.
+func driftPoints(logs []InclinationLog) []DriftPoint {
+	points := make([]DriftPoint, len(logs))
+	for i, l := range logs {
+		tilt, _ := ComputeTilt(l.acceleration(), logs[0].acceleration())
+		points[i] = DriftPoint{
+			Time:  l.Time,
+			Angle: l.AngleZ,
+			Tilt:  tilt,
+			Drift: tilt.Total,
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		hours := l.Time.Sub(logs[i-1].Time).Hours()
+		if hours > 0 {
+			change, _ := ComputeTilt(l.acceleration(), logs[i-1].acceleration())
+			points[i].Rate = change.Total / hours
+		}
+	}
+
+	return points
+}
+
+// Alerts returns alerts of trend.
+// Alert is raised when a limit starts to be exceeded, not on every reading while exceeded.
+func (t *InclinationTrend) Alerts(threshold DriftThreshold) []DriftAlert {
+	alerts := make([]DriftAlert, 0)
+
+	driftExceeded, rateExceeded := false, false
+	for _, point := range t.Points {
+		exceeded := threshold.MaxDrift > 0 && math.Abs(point.Drift) > threshold.MaxDrift
+		if exceeded && !driftExceeded {
+			alerts = append(alerts, DriftAlert{Type: DriftExceeded, Devid: t.Devid, Point: point})
+		}
+		driftExceeded = exceeded
+
+		exceeded = threshold.MaxRate > 0 && math.Abs(point.Rate) > threshold.MaxRate
+		if exceeded && !rateExceeded {
+			alerts = append(alerts, DriftAlert{Type: RateExceeded, Devid: t.Devid, Point: point})
+		}
+		rateExceeded = exceeded
+	}
+
+	return alerts
+}
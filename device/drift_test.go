@@ -0,0 +1,116 @@
+package device
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+// rolledLog returns inclination log of device rolled about X axis by degree.
+func rolledLog(at time.Time, degree float64) InclinationLog {
+	rad := degree * math.Pi / 180
+	return InclinationLog{Time: at, AccYMg: 1000 * math.Sin(rad), AccZMg: 1000 * math.Cos(rad), AngleZ: 90 - degree}
+}
+
+func TestTrend(t *testing.T) {
+	cli, _, store := newTestClient(t, &pb.Device{Devid: "dev-1", InstallSessionKey: "session-1"})
+	ctx := context.Background()
+
+	pageSize := statusLogPageSize
+	statusLogPageSize = 2
+	defer func() { statusLogPageSize = pageSize }()
+
+	base := time.Now().Add(-24 * time.Hour).Truncate(time.Hour)
+	angles := []float64{10, 10.5, 11, 14, 14.2}
+	for i, angle := range angles {
+		l := rolledLog(base.Add(time.Duration(i)*time.Hour), angle)
+		l.Devid = "dev-1"
+		l.InstallSessionKey = "session-1"
+		_ = store.PutInclinationLog(ctx, &l)
+	}
+	// Log without acceleration is skipped.
+	_ = store.PutInclinationLog(ctx, &InclinationLog{Devid: "dev-1", Time: base.Add(-time.Hour), AngleZ: 90, InstallSessionKey: "session-1"})
+	_ = store.PutInclinationLog(ctx, &InclinationLog{Devid: "dev-1", Time: base.Add(-time.Hour), AccZMg: 1000, InstallSessionKey: "session-0"})
+
+	trend, err := Trend(ctx, cli, "dev-1", "session-1")
+	assert.Nil(t, err)
+	assert.Equal(t, base, trend.Baseline.Time)
+	assert.Len(t, trend.Points, 5)
+	assert.Equal(t, float64(0), trend.Points[0].Drift)
+	assert.Equal(t, float64(0), trend.Points[0].Rate)
+	assert.InDelta(t, 4, trend.Points[3].Drift, 1e-9)
+	assert.InDelta(t, 4, trend.Points[3].Tilt.Roll, 1e-9)
+	assert.InDelta(t, 3, trend.Points[3].Rate, 1e-9)
+	assert.InDelta(t, 4.2, trend.Points[4].Drift, 1e-9)
+	assert.InDelta(t, 75.8, trend.Points[4].Angle, 1e-9)
+
+	_, err = Trend(ctx, cli, "dev-1", "session-2")
+	assert.Equal(t, ErrNoEntities, err)
+
+	_, err = Trend(ctx, cli, "unknown", "session-1")
+	assert.Equal(t, ErrNonExistDevice, err)
+}
+
+func TestTrendAlerts(t *testing.T) {
+	base := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	logs := []InclinationLog{
+		rolledLog(base, 10),
+		rolledLog(base.Add(time.Hour), 13),
+		rolledLog(base.Add(2*time.Hour), 13.5),
+		rolledLog(base.Add(3*time.Hour), 11),
+		rolledLog(base.Add(4*time.Hour), 7),
+	}
+	trend := &InclinationTrend{Devid: "dev-1", Baseline: logs[0], Points: driftPoints(logs)}
+
+	alerts := trend.Alerts(DriftThreshold{MaxDrift: 2.5, MaxRate: 2})
+	assert.Len(t, alerts, 4)
+
+	assert.Equal(t, DriftExceeded, alerts[0].Type)
+	assert.Equal(t, "dev-1", alerts[0].Devid)
+	assert.Equal(t, base.Add(time.Hour), alerts[0].Point.Time)
+	assert.Equal(t, RateExceeded, alerts[1].Type)
+	assert.Equal(t, base.Add(time.Hour), alerts[1].Point.Time)
+
+	// Back within limits at 11°, then exceeded again at 7°.
+	assert.Equal(t, RateExceeded, alerts[2].Type)
+	assert.Equal(t, base.Add(3*time.Hour), alerts[2].Point.Time)
+	assert.Equal(t, DriftExceeded, alerts[3].Type)
+	assert.InDelta(t, 3, alerts[3].Point.Drift, 1e-9)
+	assert.InDelta(t, -3, alerts[3].Point.Tilt.Roll, 1e-9)
+
+	assert.Empty(t, trend.Alerts(DriftThreshold{}))
+	assert.Equal(t, "RateExceeded", RateExceeded.String())
+}
+
+func TestTrendDirectionChange(t *testing.T) {
+	// Device keeps 30° from vertical axis while it turns around the axis,
+	// so angle of Z axis does not change.
+	base := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	logs := make([]InclinationLog, 0)
+	for i, heading := range []float64{0, 90, 180} {
+		rad := heading * math.Pi / 180
+		logs = append(logs, InclinationLog{
+			Time:   base.Add(time.Duration(i) * time.Hour),
+			AccXMg: 500 * math.Cos(rad),
+			AccYMg: 500 * math.Sin(rad),
+			AccZMg: 1000 * math.Sqrt(3) / 2,
+			AngleZ: 60,
+		})
+	}
+
+	points := driftPoints(logs)
+	assert.InDelta(t, 0, points[0].Drift, 1e-9)
+	assert.InDelta(t, math.Acos(0.75)*180/math.Pi, points[1].Drift, 1e-9)
+	assert.InDelta(t, 60, points[2].Drift, 1e-9)
+	assert.InDelta(t, math.Acos(0.75)*180/math.Pi, points[2].Rate, 1e-9)
+
+	trend := &InclinationTrend{Devid: "dev-1", Baseline: logs[0], Points: points}
+	alerts := trend.Alerts(DriftThreshold{MaxDrift: 30})
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, base.Add(time.Hour), alerts[0].Point.Time)
+}
@@ -9,6 +9,7 @@ type StatusLog struct {
 	Temperature       int       `datastore:"Temperature"`
 	Battery           int       `datastore:"Battery"`
 	RSSI              int       `datastore:"RSSI"`
+	LoRaErr           int       `datastore:"LoRaErr"`
 	InstallSessionKey string    `datastore:"InstallSessionKey"`
 }
 
@@ -20,5 +21,30 @@ type InclinationLog struct {
 	AccYMg            float64   `datastore:"acc_y_mg"`
 	AccZMg            float64   `datastore:"acc_z_mg"`
 	AngleZ            float64   `datastore:"angle_z"`
+	Pitch             float64   `datastore:"pitch"`
+	Roll              float64   `datastore:"roll"`
+	Tilt              float64   `datastore:"tilt"`
 	InstallSessionKey string    `datastore:"install_session_key"`
 }
+
+// SequenceLog defines structure of log of received frame sequence.
+type SequenceLog struct {
+	Devid string    `datastore:"Devid"`
+	Time  time.Time `datastore:"Time"`
+	Seq   int       `datastore:"Seq"`
+	// Missed is number of frames skipped before this frame.
+	Missed int `datastore:"Missed"`
+	// Duplicate is true if frame was already received.
+	Duplicate bool `datastore:"Duplicate"`
+	// Late is true if frame arrived after newer frames and was counted as missed before.
+	Late bool `datastore:"Late"`
+}
+
+// BaselineLog defines structure of log of baseline which device reports in application config.
+type BaselineLog struct {
+	Devid string    `datastore:"Devid"`
+	Time  time.Time `datastore:"Time"`
+	X     float64   `datastore:"X"`
+	Y     float64   `datastore:"Y"`
+	Z     float64   `datastore:"Z"`
+}
@@ -29,3 +29,18 @@ type FilterGroupID struct {
 }
 
 func (f FilterGroupID) isFilterGroupID() {}
+
+// Request converts filter into FilterList request.
+func (f Filter) Request() *pb.DeviceFilterListRequest {
+	req := &pb.DeviceFilterListRequest{}
+
+	if status, ok := f.InstallStatus.(FilterInstallStatus); ok {
+		req.InstallStatus = &pb.DeviceFilterListRequest_InstallStatusValue{InstallStatusValue: status.Value}
+	}
+
+	if groupID, ok := f.GroupID.(FilterGroupID); ok {
+		req.GroupId = &pb.DeviceFilterListRequest_GroupIdValue{GroupIdValue: groupID.Value}
+	}
+
+	return req
+}
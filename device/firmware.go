@@ -0,0 +1,141 @@
+package device
+
+import (
+	"context"
+	"sort"
+
+	"google.golang.org/api/iterator"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+// FirmwareInfo is firmware versions which device reported last.
+type FirmwareInfo struct {
+	Devid   string
+	DevType pb.DeviceType
+	App     parser.FirmwareVersion
+	LoRa    parser.FirmwareVersion
+	// Reported is false if device has not reported valid versions yet.
+	Reported bool
+}
+
+// FirmwarePolicy is minimum firmware versions which devices should run.
+type FirmwarePolicy struct {
+	MinApp  parser.FirmwareVersion
+	MinLoRa parser.FirmwareVersion
+	// Supported is application firmware range which each device type supports.
+	// Device types which are not in map are not checked.
+	Supported map[pb.DeviceType]parser.FirmwareRange
+}
+
+// FirmwareInventory is firmware versions of devices.
+type FirmwareInventory struct {
+	// Devices are sorted by devid.
+	Devices []FirmwareInfo
+	// ByApp and ByLoRa map version to devids which run it.
+	ByApp  map[string][]string
+	ByLoRa map[string][]string
+	// Unreported is devids which have not reported versions.
+	Unreported []string
+}
+
+// FirmwareReport returns firmware inventory of devices which filter matches.
+// Devices which are listed before error are kept in inventory.
+func FirmwareReport(ctx context.Context, cli Client, filter Filter) (*FirmwareInventory, error) {
+	it := cli.FilterListIter(ctx, filter.Request())
+	defer it.Close()
+
+	inventory := &FirmwareInventory{
+		Devices:    make([]FirmwareInfo, 0),
+		ByApp:      make(map[string][]string),
+		ByLoRa:     make(map[string][]string),
+		Unreported: make([]string, 0),
+	}
+
+	var err error
+	for {
+		var dev *pb.Device
+		dev, err = it.Next()
+		if err == iterator.Done {
+			err = nil
+			break
+		}
+
+		if err != nil {
+			break
+		}
+
+		inventory.add(firmwareOf(dev))
+	}
+
+	sort.SliceStable(inventory.Devices, func(i, j int) bool {
+		return inventory.Devices[i].Devid < inventory.Devices[j].Devid
+	})
+
+	for _, devids := range []map[string][]string{inventory.ByApp, inventory.ByLoRa} {
+		for _, ids := range devids {
+			sort.Strings(ids)
+		}
+	}
+	sort.Strings(inventory.Unreported)
+
+	return inventory, err
+}
+
+func firmwareOf(dev *pb.Device) FirmwareInfo {
+	info := FirmwareInfo{Devid: dev.Devid, DevType: dev.DevType}
+
+	app, appErr := parser.ParseFirmwareVersion(dev.AppFwVer)
+	lora, loraErr := parser.ParseFirmwareVersion(dev.LoraFwVer)
+	if appErr == nil && loraErr == nil {
+		info.App, info.LoRa, info.Reported = app, lora, true
+	}
+
+	return info
+}
+
+func (inv *FirmwareInventory) add(info FirmwareInfo) {
+	inv.Devices = append(inv.Devices, info)
+
+	if !info.Reported {
+		inv.Unreported = append(inv.Unreported, info.Devid)
+		return
+	}
+
+	inv.ByApp[info.App.String()] = append(inv.ByApp[info.App.String()], info.Devid)
+	inv.ByLoRa[info.LoRa.String()] = append(inv.ByLoRa[info.LoRa.String()], info.Devid)
+}
+
+// Outdated returns devices which run firmware older than policy.
+func (inv *FirmwareInventory) Outdated(policy FirmwarePolicy) []FirmwareInfo {
+	outdated := make([]FirmwareInfo, 0)
+	for _, info := range inv.Devices {
+		if !info.Reported {
+			continue
+		}
+
+		if info.App.Compare(policy.MinApp) < 0 || info.LoRa.Compare(policy.MinLoRa) < 0 {
+			outdated = append(outdated, info)
+		}
+	}
+
+	return outdated
+}
+
+// Unsupported returns devices which run application firmware out of range which policy supports.
+func (inv *FirmwareInventory) Unsupported(policy FirmwarePolicy) []FirmwareInfo {
+	unsupported := make([]FirmwareInfo, 0)
+	for _, info := range inv.Devices {
+		if !info.Reported {
+			continue
+		}
+
+		r, ok := policy.Supported[info.DevType]
+		if ok && !r.Contains(info.App) {
+			unsupported = append(unsupported, info)
+		}
+	}
+
+	return unsupported
+}
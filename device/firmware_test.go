@@ -0,0 +1,62 @@
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+func TestFirmwareReport(t *testing.T) {
+	cli, _, _ := newTestClient(t,
+		&pb.Device{Devid: "dev-3", DevType: pb.DeviceType_InoVibeS, AppFwVer: "2.6.3", LoraFwVer: "1.2.2"},
+		&pb.Device{Devid: "dev-1", DevType: pb.DeviceType_InoVibe, AppFwVer: "2.6.3", LoraFwVer: "1.2.0"},
+		&pb.Device{Devid: "dev-2", DevType: pb.DeviceType_InoVibe, AppFwVer: "0.9.1", LoraFwVer: "1.2.2"},
+		&pb.Device{Devid: "dev-4", DevType: pb.DeviceType_InoVibe},
+	)
+	ctx := context.Background()
+
+	inventory, err := FirmwareReport(ctx, cli, Filter{})
+	assert.Nil(t, err)
+	assert.Len(t, inventory.Devices, 4)
+	assert.Equal(t, "dev-1", inventory.Devices[0].Devid)
+	assert.Equal(t, map[string][]string{"2.6.3": {"dev-1", "dev-3"}, "0.9.1": {"dev-2"}}, inventory.ByApp)
+	assert.Equal(t, map[string][]string{"1.2.0": {"dev-1"}, "1.2.2": {"dev-2", "dev-3"}}, inventory.ByLoRa)
+	assert.Equal(t, []string{"dev-4"}, inventory.Unreported)
+
+	outdated := inventory.Outdated(FirmwarePolicy{
+		MinApp:  parser.FirmwareVersion{Major: 2, Minor: 6},
+		MinLoRa: parser.FirmwareVersion{Major: 1, Minor: 2, Rev: 1},
+	})
+	assert.Len(t, outdated, 2)
+	assert.Equal(t, "dev-1", outdated[0].Devid)
+	assert.Equal(t, "dev-2", outdated[1].Devid)
+
+	assert.Empty(t, inventory.Unsupported(FirmwarePolicy{}))
+
+	unsupported := inventory.Unsupported(FirmwarePolicy{
+		Supported: map[pb.DeviceType]parser.FirmwareRange{
+			pb.DeviceType_InoVibe: {Min: parser.FirmwareVersion{Major: 1}},
+		},
+	})
+	assert.Len(t, unsupported, 1)
+	assert.Equal(t, "dev-2", unsupported[0].Devid)
+}
+
+func TestIngestUpdatesFirmware(t *testing.T) {
+	cli, _, _ := newTestClient(t,
+		&pb.Device{Devid: "dev-1", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s1", AppFwVer: "2.5.0"},
+	)
+	ctx := context.Background()
+
+	_, err := cli.Ingest(ctx, "dev-1", testAliveFrame)
+	assert.Nil(t, err)
+
+	resp, err := cli.Detail(ctx, "dev-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "2.6.3", resp.Devices[0].AppFwVer)
+	assert.Equal(t, "1.2.2", resp.Devices[0].LoraFwVer)
+}
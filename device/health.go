@@ -0,0 +1,230 @@
+package device
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"google.golang.org/api/iterator"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+// HealthReason is reason which lowers health score of device.
+type HealthReason string
+
+// Health reasons.
+const (
+	ReasonNeverSeen       HealthReason = "never seen"
+	ReasonMissedAlive     HealthReason = "missed alives"
+	ReasonWeakSignal      HealthReason = "weak signal"
+	ReasonLoRaError       HealthReason = "lora error"
+	ReasonLowBattery      HealthReason = "low battery"
+	ReasonOverTemperature HealthReason = "over temperature"
+)
+
+// healthPenalties is score which each reason takes from 100.
+var healthPenalties = map[HealthReason]int{
+	ReasonNeverSeen:       100,
+	ReasonMissedAlive:     40,
+	ReasonWeakSignal:      20,
+	ReasonLoRaError:       10,
+	ReasonLowBattery:      20,
+	ReasonOverTemperature: 10,
+}
+
+// HealthPolicy is thresholds of health check.
+type HealthPolicy struct {
+	// MissedAlives is number of missed alive periods regarded as disconnected.
+	MissedAlives int
+	// WeakRSSI is RSSI at or below which signal is weak.
+	WeakRSSI int
+	// LowBattery is battery level in percent at or below which battery is low.
+	LowBattery int
+	// MaxTemperature is temperature above which device is overheated.
+	MaxTemperature int
+	// AlivePeriod is used if device has no period.
+	AlivePeriod time.Duration
+}
+
+// DefaultHealthPolicy is policy for typical installation.
+var DefaultHealthPolicy = HealthPolicy{
+	MissedAlives:   2,
+	WeakRSSI:       -115,
+	LowBattery:     20,
+	MaxTemperature: 60,
+	AlivePeriod:    6 * time.Hour,
+}
+
+// HealthReport is health of device from its latest status log.
+type HealthReport struct {
+	Devid    string
+	LastSeen time.Time
+	// MissedAlives is number of alive periods elapsed since last seen.
+	MissedAlives int
+	Battery      int
+	Temperature  int
+	RSSI         int
+	LoRaErr      int
+
+	// Score is 100 for healthy device and 0 for device which is never seen.
+	Score   int
+	Reasons []HealthReason
+}
+
+// Healthy returns true if device has no reason of bad health.
+func (h *HealthReport) Healthy() bool {
+	return len(h.Reasons) == 0
+}
+
+// FleetHealthSummary is health of devices.
+type FleetHealthSummary struct {
+	// Devices are sorted by score, worst first.
+	Devices      []HealthReport
+	Healthy      int
+	Reasons      map[HealthReason]int
+	AverageScore float64
+}
+
+// Health checks health of installed device.
+//
+// ErrNonExistDevice returns if device does not exist.
+// ErrForbiddenInstallStatus returns if device is not installed.
+func Health(ctx context.Context, cli Client, devid string, policy HealthPolicy) (*HealthReport, error) {
+	resp, err := cli.Detail(ctx, devid)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.ResultCode != pb.ResponseCode_SUCCESS {
+		return nil, ErrNonExistDevice
+	}
+
+	dev := resp.Devices[0]
+	if dev.InstallStatus != pb.InstallStatus_Installed {
+		return nil, ErrForbiddenInstallStatus
+	}
+
+	return deviceHealth(ctx, cli, dev, policy, time.Now())
+}
+
+// FleetHealth checks health of installed devices which filter matches.
+// Devices which are checked before error are kept in summary.
+func FleetHealth(ctx context.Context, cli Client, filter Filter, policy HealthPolicy) (*FleetHealthSummary, error) {
+	it := cli.FilterListIter(ctx, filter.Request())
+	defer it.Close()
+
+	now := time.Now()
+	summary := &FleetHealthSummary{
+		Devices: make([]HealthReport, 0),
+		Reasons: make(map[HealthReason]int),
+	}
+
+	var err error
+	for {
+		var dev *pb.Device
+		dev, err = it.Next()
+		if err == iterator.Done {
+			err = nil
+			break
+		}
+
+		if err != nil {
+			break
+		}
+
+		if dev.InstallStatus != pb.InstallStatus_Installed {
+			continue
+		}
+
+		var report *HealthReport
+		report, err = deviceHealth(ctx, cli, dev, policy, now)
+		if err != nil {
+			break
+		}
+
+		summary.add(report)
+	}
+
+	sort.SliceStable(summary.Devices, func(i, j int) bool {
+		return summary.Devices[i].Score < summary.Devices[j].Score
+	})
+
+	return summary, err
+}
+
+func (s *FleetHealthSummary) add(report *HealthReport) {
+	total := s.AverageScore * float64(len(s.Devices))
+
+	s.Devices = append(s.Devices, *report)
+	s.AverageScore = (total + float64(report.Score)) / float64(len(s.Devices))
+
+	if report.Healthy() {
+		s.Healthy++
+	}
+
+	for _, reason := range report.Reasons {
+		s.Reasons[reason]++
+	}
+}
+
+func deviceHealth(ctx context.Context, cli Client, dev *pb.Device, policy HealthPolicy, now time.Time) (*HealthReport, error) {
+	logs, err := cli.StatusLog(ctx, dev.Devid, dev.InstallSessionKey, time.Unix(0, 0), now, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &HealthReport{Devid: dev.Devid}
+	if len(logs) == 0 {
+		report.addReason(ReasonNeverSeen)
+		return report, nil
+	}
+
+	latest := logs[0]
+	report.LastSeen = latest.Time
+	report.Battery = latest.Battery
+	report.Temperature = latest.Temperature
+	report.RSSI = latest.RSSI
+	report.LoRaErr = latest.LoRaErr
+
+	period := time.Duration(dev.Period) * time.Minute
+	if period <= 0 {
+		period = policy.AlivePeriod
+	}
+
+	if period > 0 {
+		report.MissedAlives = int(now.Sub(latest.Time) / period)
+	}
+
+	report.Score = 100
+	if report.MissedAlives >= policy.MissedAlives {
+		report.addReason(ReasonMissedAlive)
+	}
+
+	if latest.RSSI <= policy.WeakRSSI {
+		report.addReason(ReasonWeakSignal)
+	}
+
+	if latest.LoRaErr != 0 {
+		report.addReason(ReasonLoRaError)
+	}
+
+	if latest.Battery <= policy.LowBattery {
+		report.addReason(ReasonLowBattery)
+	}
+
+	if latest.Temperature > policy.MaxTemperature {
+		report.addReason(ReasonOverTemperature)
+	}
+
+	return report, nil
+}
+
+func (h *HealthReport) addReason(reason HealthReason) {
+	h.Reasons = append(h.Reasons, reason)
+
+	h.Score -= healthPenalties[reason]
+	if h.Score < 0 {
+		h.Score = 0
+	}
+}
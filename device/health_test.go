@@ -0,0 +1,84 @@
+package device
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+func TestHealth(t *testing.T) {
+	cli, _, store := newTestClient(t,
+		&pb.Device{Devid: "healthy", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s1", Period: 60},
+		&pb.Device{Devid: "stale", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s2", Period: 60},
+		&pb.Device{Devid: "weak", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s3"},
+		&pb.Device{Devid: "silent", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s4"},
+		&pb.Device{Devid: "initial", InstallStatus: pb.InstallStatus_Initial},
+	)
+	ctx := context.Background()
+	now := time.Now()
+
+	_ = store.PutStatusLog(ctx, &StatusLog{Devid: "healthy", InstallSessionKey: "s1", Time: now.Add(-2 * time.Hour), Battery: 10, RSSI: -120})
+	_ = store.PutStatusLog(ctx, &StatusLog{Devid: "healthy", InstallSessionKey: "s1", Time: now.Add(-10 * time.Minute), Battery: 90, Temperature: 25, RSSI: -90})
+	_ = store.PutStatusLog(ctx, &StatusLog{Devid: "stale", InstallSessionKey: "s2", Time: now.Add(-3*time.Hour - time.Minute), Battery: 80, Temperature: 70, RSSI: -90})
+	_ = store.PutStatusLog(ctx, &StatusLog{Devid: "weak", InstallSessionKey: "s3", Time: now.Add(-time.Hour), Battery: 15, RSSI: -118, LoRaErr: 3})
+
+	report, err := Health(ctx, cli, "healthy", DefaultHealthPolicy)
+	assert.Nil(t, err)
+	assert.True(t, report.Healthy())
+	assert.Equal(t, 100, report.Score)
+	assert.Equal(t, 90, report.Battery)
+	assert.Equal(t, 0, report.MissedAlives)
+
+	report, err = Health(ctx, cli, "stale", DefaultHealthPolicy)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, report.MissedAlives)
+	assert.Equal(t, []HealthReason{ReasonMissedAlive, ReasonOverTemperature}, report.Reasons)
+	assert.Equal(t, 50, report.Score)
+
+	// Default alive period of policy is used for device without period.
+	report, err = Health(ctx, cli, "weak", DefaultHealthPolicy)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, report.MissedAlives)
+	assert.Equal(t, []HealthReason{ReasonWeakSignal, ReasonLoRaError, ReasonLowBattery}, report.Reasons)
+	assert.Equal(t, 3, report.LoRaErr)
+	assert.Equal(t, 50, report.Score)
+
+	report, err = Health(ctx, cli, "silent", DefaultHealthPolicy)
+	assert.Nil(t, err)
+	assert.Equal(t, []HealthReason{ReasonNeverSeen}, report.Reasons)
+	assert.Equal(t, 0, report.Score)
+
+	_, err = Health(ctx, cli, "initial", DefaultHealthPolicy)
+	assert.Equal(t, ErrForbiddenInstallStatus, err)
+
+	_, err = Health(ctx, cli, "unknown", DefaultHealthPolicy)
+	assert.Equal(t, ErrNonExistDevice, err)
+}
+
+func TestFleetHealth(t *testing.T) {
+	cli, _, store := newTestClient(t,
+		&pb.Device{Devid: "healthy", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s1", Period: 60},
+		&pb.Device{Devid: "hot", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s2", Period: 60},
+		&pb.Device{Devid: "silent", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s3"},
+		&pb.Device{Devid: "initial", InstallStatus: pb.InstallStatus_Initial},
+	)
+	ctx := context.Background()
+	now := time.Now()
+
+	_ = store.PutStatusLog(ctx, &StatusLog{Devid: "healthy", InstallSessionKey: "s1", Time: now, Battery: 90, RSSI: -90})
+	_ = store.PutStatusLog(ctx, &StatusLog{Devid: "hot", InstallSessionKey: "s2", Time: now, Battery: 90, Temperature: 80, RSSI: -90})
+
+	summary, err := FleetHealth(ctx, cli, Filter{}, DefaultHealthPolicy)
+	assert.Nil(t, err)
+	assert.Len(t, summary.Devices, 3)
+	assert.Equal(t, "silent", summary.Devices[0].Devid)
+	assert.Equal(t, "hot", summary.Devices[1].Devid)
+	assert.Equal(t, "healthy", summary.Devices[2].Devid)
+	assert.Equal(t, 1, summary.Healthy)
+	assert.Equal(t, map[HealthReason]int{ReasonNeverSeen: 1, ReasonOverTemperature: 1}, summary.Reasons)
+	assert.InDelta(t, 190.0/3, summary.AverageScore, 1e-9)
+}
@@ -0,0 +1,259 @@
+package device
+
+import (
+	"context"
+	"time"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+// IngestResult describes frame which Ingest parsed and logs which are stored from it.
+type IngestResult struct {
+	Header *parser.Header
+	// Payload is *parser.AlivePayload, *parser.WavePayload, notice which parser.FrameParser returns or nil.
+	Payload interface{}
+	// Duplicate is true if frame with same sequence was already ingested. Nothing is stored then.
+	Duplicate bool
+	Sequence  *SequenceLog
+
+	StatusLog      *StatusLog
+	InclinationLog *InclinationLog
+}
+
+// Ingest parses raw frame of device and stores logs from it.
+// Sequence of frame is reserved before logs are stored and committed once status log is stored,
+// so redelivered frame is stored once even if it arrives concurrently.
+// Reservation is released if nothing is stored so that redelivery is stored again.
+// Inclination log and firmware of frame whose storing failed after commit are not retried by redelivery.
+// Status log is stored from header of every frame and inclination log is stored from alive payload.
+// Inclination is calculated against baseline of latest application config notice, which is kept in LogStore.
+// Firmware versions of device are updated if alive payload reports different ones.
+//
+// ErrNonExistDevice returns if requested device is not exist.
+// ErrForbiddenInstallStatus returns if requested device is not installed. Result has parsed frame.
+// Errors of parser return if frame is invalid.
+func (c *client) Ingest(ctx context.Context, devid, rawHex string) (*IngestResult, error) {
+	frame, err := parser.NewFrameParser(rawHex)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := frame.Header()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &IngestResult{Header: header}
+
+	tracker, err := c.getSequenceTracker(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seq := int(header.Seq)
+	reserved, err := tracker.Reserve(ctx, devid, seq)
+	if err != nil {
+		return nil, err
+	}
+
+	if !reserved {
+		result.Duplicate = true
+		result.Sequence, err = tracker.Track(ctx, devid, seq, time.Now())
+		return result, err
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			tracker.Release(devid, seq)
+		}
+	}()
+
+	switch header.Payload.Type {
+	case parser.AliveType:
+		result.Payload, err = frame.Alive()
+	case parser.WaveType:
+		result.Payload, err = frame.Wave()
+	case parser.NoticeType:
+		result.Payload, err = frame.Notice()
+		if err == parser.ErrInvalidType {
+			// Notice which parser does not know is stored as status only.
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := c.getDevice(ctx, devid)
+	if err != nil {
+		return result, err
+	}
+
+	// Nothing is stored for device which is not installed.
+	if device.InstallStatus != pb.InstallStatus_Installed {
+		return result, ErrForbiddenInstallStatus
+	}
+
+	// Baseline is same for redelivered frame, so storing it again is harmless.
+	if cfg, ok := result.Payload.(parser.ApplicationConfig); ok {
+		if err := c.putBaseline(ctx, devid, ConfigBaseline(&cfg)); err != nil {
+			return result, err
+		}
+	}
+
+	result.StatusLog, err = c.putStatusLog(ctx, device, StatusLog{
+		Battery:     int(header.Battery),
+		Temperature: int(header.Temperature),
+		RSSI:        int(header.RSSI),
+		LoRaErr:     int(header.LoRaErr),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// Frame is committed once status log is stored, so that redelivery does not store it twice.
+	committed = true
+	result.Sequence, err = tracker.Commit(ctx, devid, seq, time.Now())
+	if err != nil {
+		return result, err
+	}
+
+	if alive, ok := result.Payload.(*parser.AlivePayload); ok {
+		baseline, err := c.baseline(ctx, devid)
+		if err != nil {
+			return result, err
+		}
+
+		result.InclinationLog, err = c.putInclinationLog(ctx, device, alive.X, alive.Y, alive.Z, baseline)
+		if err == ErrInvalidInclinationValue {
+			// Zero acceleration is reported by device which has no measurement yet.
+			err = nil
+		}
+		if err != nil {
+			return result, err
+		}
+
+		if err := c.updateFirmware(ctx, device, alive); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// PacketStats returns statistics of frames which Ingest received from device within time range.
+//
+// ErrInvalidParameter returns if time range is invalid.
+func (c *client) PacketStats(ctx context.Context, devid string, timeFrom, timeTo time.Time) (*PacketStats, error) {
+	tracker, err := c.getSequenceTracker(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return tracker.Stats(ctx, devid, timeFrom, timeTo)
+}
+
+// updateFirmware updates firmware versions of device if alive reports different ones.
+func (c *client) updateFirmware(ctx context.Context, device *pb.Device, alive *parser.AlivePayload) error {
+	appVer, loraVer := alive.AppFirmware().String(), alive.LoRaFirmware().String()
+	if device.AppFwVer == appVer && device.LoraFwVer == loraVer {
+		return nil
+	}
+
+	resp, err := c.UpdateInfo(ctx, &pb.DeviceInfoUpdateRequest{
+		Devid:     device.Devid,
+		AppFwVer:  &pb.DeviceInfoUpdateRequest_AppFwVerValue{AppFwVerValue: appVer},
+		LoraFwVer: &pb.DeviceInfoUpdateRequest_LoraFwVerValue{LoraFwVerValue: loraVer},
+	})
+	if err != nil {
+		return err
+	}
+
+	return responseError(resp.ResultCode, ErrUpdateFailed)
+}
+
+func (c *client) getSequenceTracker(ctx context.Context) (*SequenceTracker, error) {
+	store, err := c.getLogStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.ingestMu.Lock()
+	defer c.ingestMu.Unlock()
+
+	if c.sequences == nil {
+		c.sequences = NewSequenceTracker(store)
+	}
+
+	return c.sequences, nil
+}
+
+// baseline returns baseline of latest application config of device.
+// It is loaded from LogStore at first, and DefaultBaseline returns if device has not reported it.
+func (c *client) baseline(ctx context.Context, devid string) (Acceleration, error) {
+	c.ingestMu.Lock()
+	baseline, ok := c.baselines[devid]
+	c.ingestMu.Unlock()
+
+	if ok {
+		return baseline, nil
+	}
+
+	store, err := c.getLogStore(ctx)
+	if err != nil {
+		return Acceleration{}, err
+	}
+
+	latest, err := store.LastBaselineLog(ctx, devid)
+	switch {
+	case err == ErrNoEntities:
+		baseline = DefaultBaseline
+	case err != nil:
+		return Acceleration{}, err
+	default:
+		baseline = Acceleration{X: latest.X, Y: latest.Y, Z: latest.Z}
+	}
+
+	c.ingestMu.Lock()
+	defer c.ingestMu.Unlock()
+
+	// Baseline which is put meanwhile is newer.
+	if cached, ok := c.baselines[devid]; ok {
+		return cached, nil
+	}
+
+	c.cacheBaseline(devid, baseline)
+
+	return baseline, nil
+}
+
+// putBaseline stores baseline of device in LogStore.
+func (c *client) putBaseline(ctx context.Context, devid string, baseline Acceleration) error {
+	store, err := c.getLogStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	newLog := BaselineLog{Devid: devid, Time: time.Now(), X: baseline.X, Y: baseline.Y, Z: baseline.Z}
+	if err := store.PutBaselineLog(ctx, &newLog); err != nil {
+		return err
+	}
+
+	c.ingestMu.Lock()
+	defer c.ingestMu.Unlock()
+
+	c.cacheBaseline(devid, baseline)
+
+	return nil
+}
+
+// cacheBaseline keeps baseline of device. Caller should hold ingestMu.
+func (c *client) cacheBaseline(devid string, baseline Acceleration) {
+	if c.baselines == nil {
+		c.baselines = make(map[string]Acceleration)
+	}
+
+	c.baselines[devid] = baseline
+}
@@ -0,0 +1,204 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+const (
+	testAliveFrame  = "0302f411150092100064003bff07ffe3016801044c01000101010c0102060301020223"
+	testWaveFrame   = "0302e30113009d80000d1c01ffdfffd800e8ffedffbc00e4fff6ffd300d0fff2ffe400e3fff3ffd000effff2ffe200f4ffe7fff400effff8ffee00d3ae"
+	testConfigFrame = "0302d3641a00ca500c87071c0204076c040c01400000000000000000000000000000000000000000f0"
+)
+
+func TestIngest(t *testing.T) {
+	cli, _, store := newTestClient(t,
+		&pb.Device{Devid: "dev-1", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s1", DevType: pb.DeviceType_InoVibe},
+	)
+	ctx := context.Background()
+
+	result, err := cli.Ingest(ctx, "dev-1", testAliveFrame)
+	assert.Nil(t, err)
+	assert.False(t, result.Duplicate)
+	assert.Equal(t, uint32(244), result.Header.Seq)
+	assert.IsType(t, &parser.AlivePayload{}, result.Payload)
+	assert.Equal(t, 17, result.StatusLog.Battery)
+	assert.Equal(t, 21, result.StatusLog.Temperature)
+	assert.Equal(t, -110, result.StatusLog.RSSI)
+	assert.Equal(t, "s1", result.StatusLog.InstallSessionKey)
+	assert.Equal(t, 59*3.9, result.InclinationLog.AccXMg)
+	assert.Equal(t, -249*3.9, result.InclinationLog.AccYMg)
+
+	latest, err := store.LastInclinationLog(ctx, "dev-1")
+	assert.Nil(t, err)
+	assert.Equal(t, result.InclinationLog.Tilt, latest.Tilt)
+
+	// Redelivered frame is not stored again.
+	result, err = cli.Ingest(ctx, "dev-1", testAliveFrame)
+	assert.Nil(t, err)
+	assert.True(t, result.Duplicate)
+	assert.Nil(t, result.StatusLog)
+
+	result, err = cli.Ingest(ctx, "dev-1", testWaveFrame)
+	assert.Nil(t, err)
+	assert.IsType(t, &parser.WavePayload{}, result.Payload)
+	assert.NotNil(t, result.StatusLog)
+	assert.Nil(t, result.InclinationLog)
+
+	result, err = cli.Ingest(ctx, "dev-1", testConfigFrame)
+	assert.Nil(t, err)
+	assert.IsType(t, parser.ApplicationConfig{}, result.Payload)
+	assert.NotNil(t, result.StatusLog)
+
+	logs, err := store.StatusLogs(ctx, "dev-1", "s1", time.Now().Add(-time.Minute), time.Now(), 0, 10)
+	assert.Nil(t, err)
+	assert.Len(t, logs, 3)
+
+	baseline, err := store.LastBaselineLog(ctx, "dev-1")
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultBaseline, Acceleration{X: baseline.X, Y: baseline.Y, Z: baseline.Z})
+}
+
+func TestIngestConcurrentDuplicates(t *testing.T) {
+	cli, _, store := newTestClient(t,
+		&pb.Device{Devid: "dev-1", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s1", DevType: pb.DeviceType_InoVibe},
+	)
+	ctx := context.Background()
+
+	const count = 8
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		duplicates int
+	)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result, err := cli.Ingest(ctx, "dev-1", testAliveFrame)
+			assert.Nil(t, err)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if result.Duplicate {
+				duplicates++
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, count-1, duplicates)
+
+	logs, err := store.StatusLogs(ctx, "dev-1", "s1", time.Now().Add(-time.Minute), time.Now(), 0, 10)
+	assert.Nil(t, err)
+	assert.Len(t, logs, 1)
+}
+
+func TestIngestStoredBaseline(t *testing.T) {
+	_, srv, store := newTestClient(t,
+		&pb.Device{Devid: "dev-1", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s1", DevType: pb.DeviceType_InoVibe},
+	)
+	ctx := context.Background()
+
+	// Baseline which previous process stored.
+	baseline := Acceleration{X: 1000}
+	_ = store.PutBaselineLog(ctx, &BaselineLog{Devid: "dev-1", Time: time.Now(), X: baseline.X})
+
+	conn, err := srv.Dial(ctx)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	cli, _ := NewClient(WithConn(conn), WithLogStore(store))
+
+	result, err := cli.Ingest(ctx, "dev-1", testAliveFrame)
+	assert.Nil(t, err)
+
+	l := result.InclinationLog
+	tilt, _ := ComputeTilt(Acceleration{X: l.AccXMg, Y: l.AccYMg, Z: l.AccZMg}, baseline)
+	assert.Equal(t, tilt.Total, l.Tilt)
+
+	// Inclination stored directly is relative to same baseline.
+	raw := func(mg float64) int { return int(math.Round(mg / 3.9)) }
+	angleZ, err := cli.StoreInclinationLog(ctx, "dev-1", raw(l.AccXMg), raw(l.AccYMg), raw(l.AccZMg))
+	assert.Nil(t, err)
+
+	latest, err := store.LastInclinationLog(ctx, "dev-1")
+	assert.Nil(t, err)
+	assert.Equal(t, l.Tilt, latest.Tilt)
+	assert.Equal(t, l.AngleZ, angleZ)
+}
+
+func TestIngestError(t *testing.T) {
+	cli, _, store := newTestClient(t, &pb.Device{Devid: "initial", InstallStatus: pb.InstallStatus_Initial})
+	ctx := context.Background()
+
+	result, err := cli.Ingest(ctx, "initial", testAliveFrame)
+	assert.Equal(t, ErrForbiddenInstallStatus, err)
+	assert.Equal(t, uint32(244), result.Header.Seq)
+	assert.Nil(t, result.StatusLog)
+
+	// Frame which failed to be stored is released and not regarded as duplicate.
+	result, err = cli.Ingest(ctx, "initial", testAliveFrame)
+	assert.Equal(t, ErrForbiddenInstallStatus, err)
+	assert.False(t, result.Duplicate)
+
+	// Nothing is stored for device which is not installed.
+	_, err = cli.Ingest(ctx, "initial", testConfigFrame)
+	assert.Equal(t, ErrForbiddenInstallStatus, err)
+
+	_, err = store.LastBaselineLog(ctx, "initial")
+	assert.Equal(t, ErrNoEntities, err)
+
+	_, err = cli.Ingest(ctx, "unknown", testAliveFrame)
+	assert.Equal(t, ErrNonExistDevice, err)
+
+	_, err = cli.Ingest(ctx, "initial", "0302f411150")
+	assert.Equal(t, parser.ErrInvalidFrame, err)
+}
+
+// failingInclinationStore fails to store inclination logs.
+type failingInclinationStore struct {
+	LogStore
+}
+
+func (s *failingInclinationStore) PutInclinationLog(ctx context.Context, newLog *InclinationLog) error {
+	return errors.New("Unavailable")
+}
+
+func TestIngestPartialFailure(t *testing.T) {
+	_, srv, store := newTestClient(t,
+		&pb.Device{Devid: "dev-1", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s1", DevType: pb.DeviceType_InoVibe},
+	)
+	ctx := context.Background()
+
+	conn, err := srv.Dial(ctx)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	cli, _ := NewClient(WithConn(conn), WithLogStore(&failingInclinationStore{LogStore: store}))
+
+	result, err := cli.Ingest(ctx, "dev-1", testAliveFrame)
+	assert.NotNil(t, err)
+	assert.NotNil(t, result.StatusLog)
+	assert.NotNil(t, result.Sequence)
+
+	// Frame is committed with its status log, so redelivery does not store status log twice.
+	result, err = cli.Ingest(ctx, "dev-1", testAliveFrame)
+	assert.Nil(t, err)
+	assert.True(t, result.Duplicate)
+
+	logs, err := store.StatusLogs(ctx, "dev-1", "s1", time.Now().Add(-time.Minute), time.Now(), 0, 10)
+	assert.Nil(t, err)
+	assert.Len(t, logs, 1)
+}
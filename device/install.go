@@ -0,0 +1,113 @@
+package device
+
+import (
+	"fmt"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+// InstallRequest is request which changes install status of device.
+type InstallRequest string
+
+// Install requests.
+const (
+	RequestPrepareInstall      InstallRequest = "PrepareInstall"
+	RequestWaitCompleteInstall InstallRequest = "WaitCompleteInstall"
+	RequestCompleteInstall     InstallRequest = "CompleteInstall"
+	RequestUninstalling        InstallRequest = "Uninstalling"
+	RequestUninstall           InstallRequest = "Uninstall"
+	RequestDiscard             InstallRequest = "Discard"
+)
+
+type installTransition struct {
+	from []pb.InstallStatus
+	to   pb.InstallStatus
+}
+
+var installTransitions = map[InstallRequest]installTransition{
+	RequestPrepareInstall: {
+		// Discarded device can be installed again.
+		from: []pb.InstallStatus{pb.InstallStatus_Initial, pb.InstallStatus_Discarded},
+		to:   pb.InstallStatus_Requested,
+	},
+	RequestWaitCompleteInstall: {
+		from: []pb.InstallStatus{pb.InstallStatus_Requested},
+		to:   pb.InstallStatus_WaitInstallComplete,
+	},
+	RequestCompleteInstall: {
+		from: []pb.InstallStatus{pb.InstallStatus_Requested, pb.InstallStatus_WaitInstallComplete},
+		to:   pb.InstallStatus_Installed,
+	},
+	RequestUninstalling: {
+		from: []pb.InstallStatus{pb.InstallStatus_Installed},
+		to:   pb.InstallStatus_Uninstalling,
+	},
+	RequestUninstall: {
+		from: []pb.InstallStatus{pb.InstallStatus_Installed, pb.InstallStatus_Uninstalling},
+		to:   pb.InstallStatus_Initial,
+	},
+	RequestDiscard: {
+		from: []pb.InstallStatus{
+			pb.InstallStatus_Requested,
+			pb.InstallStatus_WaitInstallComplete,
+			pb.InstallStatus_Installed,
+			pb.InstallStatus_Uninstalling,
+		},
+		to: pb.InstallStatus_Discarded,
+	},
+}
+
+// InstallStatusError describes install request which is not permitted on current install status.
+// errors.Is reports true for ErrForbiddenInstallStatus.
+type InstallStatusError struct {
+	Devid     string
+	Request   InstallRequest
+	Current   pb.InstallStatus
+	Attempted pb.InstallStatus
+}
+
+func (e *InstallStatusError) Error() string {
+	return fmt.Sprintf("%s: %s on %s, %s -> %s", ErrForbiddenInstallStatus, e.Request, e.Devid, e.Current, e.Attempted)
+}
+
+// Unwrap returns ErrForbiddenInstallStatus.
+func (e *InstallStatusError) Unwrap() error {
+	return ErrForbiddenInstallStatus
+}
+
+// NextInstallStatus returns install status after request is applied on current status.
+// ErrInvalidParameter returns on unknown request.
+// InstallStatusError returns if request is not permitted on current status.
+func NextInstallStatus(current pb.InstallStatus, req InstallRequest) (pb.InstallStatus, error) {
+	transition, ok := installTransitions[req]
+	if !ok {
+		return current, ErrInvalidParameter
+	}
+
+	for _, from := range transition.from {
+		if from == current {
+			return transition.to, nil
+		}
+	}
+
+	return current, &InstallStatusError{Request: req, Current: current, Attempted: transition.to}
+}
+
+// PermittedInstallRequests returns install requests which are permitted on current status.
+func PermittedInstallRequests(current pb.InstallStatus) []InstallRequest {
+	requests := make([]InstallRequest, 0)
+	for _, req := range []InstallRequest{
+		RequestPrepareInstall,
+		RequestWaitCompleteInstall,
+		RequestCompleteInstall,
+		RequestUninstalling,
+		RequestUninstall,
+		RequestDiscard,
+	} {
+		if _, err := NextInstallStatus(current, req); err == nil {
+			requests = append(requests, req)
+		}
+	}
+
+	return requests
+}
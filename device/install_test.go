@@ -0,0 +1,97 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+func TestNextInstallStatus(t *testing.T) {
+	tests := []struct {
+		Current pb.InstallStatus
+		Request InstallRequest
+		Next    pb.InstallStatus
+		Err     error
+	}{
+		{pb.InstallStatus_Initial, RequestPrepareInstall, pb.InstallStatus_Requested, nil},
+		{pb.InstallStatus_Discarded, RequestPrepareInstall, pb.InstallStatus_Requested, nil},
+		{pb.InstallStatus_Requested, RequestWaitCompleteInstall, pb.InstallStatus_WaitInstallComplete, nil},
+		{pb.InstallStatus_Requested, RequestCompleteInstall, pb.InstallStatus_Installed, nil},
+		{pb.InstallStatus_WaitInstallComplete, RequestCompleteInstall, pb.InstallStatus_Installed, nil},
+		{pb.InstallStatus_Installed, RequestUninstalling, pb.InstallStatus_Uninstalling, nil},
+		{pb.InstallStatus_Installed, RequestUninstall, pb.InstallStatus_Initial, nil},
+		{pb.InstallStatus_Uninstalling, RequestUninstall, pb.InstallStatus_Initial, nil},
+		{pb.InstallStatus_Uninstalling, RequestDiscard, pb.InstallStatus_Discarded, nil},
+		{
+			pb.InstallStatus_Installed, RequestPrepareInstall, pb.InstallStatus_Installed,
+			&InstallStatusError{Request: RequestPrepareInstall, Current: pb.InstallStatus_Installed, Attempted: pb.InstallStatus_Requested},
+		},
+		{
+			pb.InstallStatus_Initial, RequestDiscard, pb.InstallStatus_Initial,
+			&InstallStatusError{Request: RequestDiscard, Current: pb.InstallStatus_Initial, Attempted: pb.InstallStatus_Discarded},
+		},
+		{
+			pb.InstallStatus_Discarded, RequestUninstall, pb.InstallStatus_Discarded,
+			&InstallStatusError{Request: RequestUninstall, Current: pb.InstallStatus_Discarded, Attempted: pb.InstallStatus_Initial},
+		},
+		{pb.InstallStatus_Initial, InstallRequest("Unknown"), pb.InstallStatus_Initial, ErrInvalidParameter},
+	}
+
+	for _, test := range tests {
+		next, err := NextInstallStatus(test.Current, test.Request)
+
+		assert.Equal(t, test.Next, next)
+		assert.Equal(t, test.Err, err)
+	}
+}
+
+func TestPermittedInstallRequests(t *testing.T) {
+	assert.Equal(t, []InstallRequest{RequestPrepareInstall}, PermittedInstallRequests(pb.InstallStatus_Initial))
+	assert.Equal(t,
+		[]InstallRequest{RequestWaitCompleteInstall, RequestCompleteInstall, RequestDiscard},
+		PermittedInstallRequests(pb.InstallStatus_Requested))
+	assert.Equal(t, []InstallRequest{RequestPrepareInstall}, PermittedInstallRequests(pb.InstallStatus_Discarded))
+}
+
+func TestInstallStatusError(t *testing.T) {
+	err := error(&InstallStatusError{
+		Devid:     "ino-vibe-test",
+		Request:   RequestUninstalling,
+		Current:   pb.InstallStatus_Requested,
+		Attempted: pb.InstallStatus_Uninstalling,
+	})
+
+	assert.True(t, errors.Is(err, ErrForbiddenInstallStatus))
+	assert.Equal(t,
+		"Request is not permitted on current install status: Uninstalling on ino-vibe-test, Requested -> Uninstalling",
+		err.Error())
+}
+
+func TestInstallValidateOnClient(t *testing.T) {
+	cli, srv, _ := newTestClient(t, &pb.Device{Devid: "ino-vibe-test", InstallStatus: pb.InstallStatus_Requested})
+	ctx := context.Background()
+
+	resp, err := cli.Uninstall(ctx, &pb.UninstallRequest{Devid: "ino-vibe-test"})
+	assert.Nil(t, resp)
+	assert.Equal(t, &InstallStatusError{
+		Devid:     "ino-vibe-test",
+		Request:   RequestUninstall,
+		Current:   pb.InstallStatus_Requested,
+		Attempted: pb.InstallStatus_Initial,
+	}, err)
+
+	// Rejected request never reaches server.
+	assert.Equal(t, pb.InstallStatus_Requested, srv.Device("ino-vibe-test").InstallStatus)
+
+	_, err = cli.Discard(ctx, &pb.DiscardRequest{Devid: "unknown"})
+	assert.Equal(t, ErrNonExistDevice, err)
+
+	waitResp, err := cli.WaitCompleteInstall(ctx, &pb.WaitCompleteInstallRequest{Devid: "ino-vibe-test"})
+	assert.Nil(t, err)
+	assert.Equal(t, pb.ResponseCode_SUCCESS, waitResp.ResponseCode)
+	assert.Equal(t, pb.InstallStatus_WaitInstallComplete, srv.Device("ino-vibe-test").InstallStatus)
+}
@@ -0,0 +1,385 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+const (
+	defaultInstallPollInterval    = 5 * time.Second
+	defaultInstallAckTimeout      = 5 * time.Minute
+	defaultInstallBaselineTimeout = 10 * time.Minute
+	defaultInstallRollbackTimeout = 30 * time.Second
+)
+
+// Errors of guided install.
+var (
+	ErrInstallFailed  = errors.New("Install request is failed")
+	ErrInstallAborted = errors.New("Install is aborted on server")
+)
+
+// InstallStage is step of guided install.
+type InstallStage int
+
+// Install stages.
+const (
+	StageNone InstallStage = iota
+	StagePrepared
+	StageAcknowledged
+	StageBaselineCaptured
+	StageCompleted
+)
+
+func (s InstallStage) String() string {
+	switch s {
+	case StagePrepared:
+		return "Prepared"
+	case StageAcknowledged:
+		return "Acknowledged"
+	case StageBaselineCaptured:
+		return "BaselineCaptured"
+	case StageCompleted:
+		return "Completed"
+	}
+	return "None"
+}
+
+// InstallProgress is reported whenever guided install reaches new stage.
+type InstallProgress struct {
+	Stage             InstallStage
+	Devid             string
+	InstallSessionKey string
+	Baseline          *InclinationLog
+}
+
+// InstallError describes failure of guided install and result of rollback.
+type InstallError struct {
+	Devid string
+	// Stage is last stage which install reached.
+	Stage       InstallStage
+	Err         error
+	RollbackErr error
+}
+
+func (e *InstallError) Error() string {
+	msg := fmt.Sprintf("install %s failed after %s: %v", e.Devid, e.Stage, e.Err)
+	if e.RollbackErr != nil {
+		msg += fmt.Sprintf(", rollback failed: %v", e.RollbackErr)
+	}
+	return msg
+}
+
+// Unwrap returns cause of failure.
+func (e *InstallError) Unwrap() error {
+	return e.Err
+}
+
+// Installer drives install of device through PrepareInstall, WaitCompleteInstall and CompleteInstall.
+//
+// Device is acknowledged when it reports after PrepareInstall or its setup notice is passed to NotifySetup,
+// and Installer moves it to WaitInstallComplete then.
+// Baseline is captured from first acceleration the device reports after PrepareInstall.
+// Install is rolled back by Uninstall or Discard if any step fails, and discarded device can be installed again.
+type Installer struct {
+	Client Client
+
+	// PollInterval is interval of checking device status.
+	PollInterval time.Duration
+	// AckTimeout limits waiting for device acknowledgement. Zero means no limit except context.
+	AckTimeout time.Duration
+	// BaselineTimeout limits waiting for baseline inclination. Zero means no limit except context.
+	BaselineTimeout time.Duration
+	// RollbackTimeout limits rollback which runs even if context is canceled.
+	RollbackTimeout time.Duration
+
+	// OnProgress is called synchronously on each stage.
+	OnProgress func(InstallProgress)
+
+	mu     sync.Mutex
+	setups map[string]bool
+}
+
+// NewInstaller creates Installer with default intervals and timeouts.
+func NewInstaller(cli Client) *Installer {
+	return &Installer{
+		Client:          cli,
+		PollInterval:    defaultInstallPollInterval,
+		AckTimeout:      defaultInstallAckTimeout,
+		BaselineTimeout: defaultInstallBaselineTimeout,
+		RollbackTimeout: defaultInstallRollbackTimeout,
+	}
+}
+
+// NotifySetup passes setup notice of device which is being installed, e.g. from Ingest.
+// Notice of device which is not being installed is ignored.
+func (i *Installer) NotifySetup(devid string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.setups[devid]; ok {
+		i.setups[devid] = true
+	}
+}
+
+func (i *Installer) watchSetup(devid string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.setups == nil {
+		i.setups = make(map[string]bool)
+	}
+	i.setups[devid] = false
+}
+
+func (i *Installer) unwatchSetup(devid string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.setups, devid)
+}
+
+func (i *Installer) setupNotified(devid string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.setups[devid]
+}
+
+// Install installs device and returns final progress which contains install session and baseline.
+// InstallError returns on failure.
+func (i *Installer) Install(ctx context.Context, req *pb.PrepareInstallRequest) (*InstallProgress, error) {
+	progress := &InstallProgress{Devid: req.Devid}
+
+	i.watchSetup(req.Devid)
+	defer i.unwatchSetup(req.Devid)
+
+	prepResp, err := i.Client.PrepareInstall(ctx, req)
+	if err == nil {
+		err = responseError(prepResp.ResponseCode, ErrInstallFailed)
+	}
+	if err != nil {
+		return nil, &InstallError{Devid: req.Devid, Stage: StageNone, Err: err}
+	}
+
+	progress.InstallSessionKey = prepResp.InstallSessionKey
+
+	// Reports of device are told by UpdateDate of server, so clock of client is not compared with it.
+	preparedAt, err := i.updateDate(ctx, req.Devid)
+	if err != nil {
+		return nil, &InstallError{
+			Devid:       req.Devid,
+			Stage:       StagePrepared,
+			Err:         err,
+			RollbackErr: i.rollback(req.Devid),
+		}
+	}
+
+	i.report(progress, StagePrepared)
+
+	if err := i.install(ctx, progress, preparedAt); err != nil {
+		return nil, &InstallError{
+			Devid:       req.Devid,
+			Stage:       progress.Stage,
+			Err:         err,
+			RollbackErr: i.rollback(req.Devid),
+		}
+	}
+
+	return progress, nil
+}
+
+// updateDate returns UpdateDate of device on server, or zero time if device has never reported.
+func (i *Installer) updateDate(ctx context.Context, devid string) (time.Time, error) {
+	resp, err := i.Client.Detail(ctx, devid)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if resp.ResultCode != pb.ResponseCode_SUCCESS {
+		return time.Time{}, ErrNonExistDevice
+	}
+
+	if resp.Devices[0].UpdateDate == nil {
+		return time.Time{}, nil
+	}
+
+	return ptypes.Timestamp(resp.Devices[0].UpdateDate)
+}
+
+func (i *Installer) install(ctx context.Context, progress *InstallProgress, preparedAt time.Time) error {
+	reportedSincePrepared := func(dev *pb.Device) bool {
+		updated, err := ptypes.Timestamp(dev.UpdateDate)
+		return err == nil && updated.After(preparedAt)
+	}
+
+	dev, err := i.poll(ctx, progress.Devid, i.AckTimeout, func(dev *pb.Device) (bool, error) {
+		switch dev.InstallStatus {
+		case pb.InstallStatus_Requested:
+			return i.setupNotified(dev.Devid) || reportedSincePrepared(dev), nil
+		case pb.InstallStatus_WaitInstallComplete:
+			return true, nil
+		}
+		return false, ErrInstallAborted
+	})
+	if err != nil {
+		return err
+	}
+
+	if dev.InstallStatus == pb.InstallStatus_Requested {
+		waitResp, err := i.Client.WaitCompleteInstall(ctx, &pb.WaitCompleteInstallRequest{Devid: progress.Devid})
+		if err != nil {
+			return err
+		}
+
+		if err := responseError(waitResp.ResponseCode, ErrInstallFailed); err != nil {
+			return err
+		}
+	}
+
+	i.report(progress, StageAcknowledged)
+
+	dev, err = i.poll(ctx, progress.Devid, i.BaselineTimeout, func(dev *pb.Device) (bool, error) {
+		if dev.InstallStatus != pb.InstallStatus_WaitInstallComplete {
+			return false, ErrInstallAborted
+		}
+
+		if !reportedSincePrepared(dev) {
+			return false, nil
+		}
+
+		return dev.AccXMg != 0 || dev.AccYMg != 0 || dev.AccZMg != 0, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	updated, _ := ptypes.Timestamp(dev.UpdateDate)
+	progress.Baseline = &InclinationLog{
+		Devid:             dev.Devid,
+		Time:              updated,
+		AccXMg:            dev.AccXMg,
+		AccYMg:            dev.AccYMg,
+		AccZMg:            dev.AccZMg,
+		AngleZ:            angle(dev.AccXMg, dev.AccYMg, dev.AccZMg),
+		InstallSessionKey: progress.InstallSessionKey,
+	}
+	i.report(progress, StageBaselineCaptured)
+
+	completeResp, err := i.Client.CompleteInstall(ctx, &pb.CompleteInstallRequest{
+		Devid:             progress.Devid,
+		InstallSessionKey: progress.InstallSessionKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := responseError(completeResp.ResponseCode, ErrInstallFailed); err != nil {
+		return err
+	}
+
+	i.report(progress, StageCompleted)
+
+	return nil
+}
+
+func (i *Installer) report(progress *InstallProgress, stage InstallStage) {
+	progress.Stage = stage
+	if i.OnProgress != nil {
+		i.OnProgress(*progress)
+	}
+}
+
+// poll checks device until done returns true or error.
+func (i *Installer) poll(ctx context.Context, devid string, timeout time.Duration, done func(*pb.Device) (bool, error)) (*pb.Device, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	interval := i.PollInterval
+	if interval <= 0 {
+		interval = defaultInstallPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		resp, err := i.Client.Detail(ctx, devid)
+		if err == nil && resp.ResultCode != pb.ResponseCode_SUCCESS {
+			err = ErrNonExistDevice
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+
+		dev := resp.Devices[0]
+		ok, err := done(dev)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			return dev, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// rollback returns device to state before install.
+// Uninstall is preferred and Discard is used if device is not installed yet.
+func (i *Installer) rollback(devid string) error {
+	timeout := i.RollbackTimeout
+	if timeout <= 0 {
+		timeout = defaultInstallRollbackTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resp, err := i.Client.Detail(ctx, devid)
+	if err != nil {
+		return err
+	}
+
+	if resp.ResultCode != pb.ResponseCode_SUCCESS {
+		return ErrNonExistDevice
+	}
+
+	status := resp.Devices[0].InstallStatus
+
+	if _, err := NextInstallStatus(status, RequestUninstall); err == nil {
+		uninstallResp, err := i.Client.Uninstall(ctx, &pb.UninstallRequest{Devid: devid})
+		if err != nil {
+			return err
+		}
+		return responseError(uninstallResp.ResponseCode, ErrInstallFailed)
+	}
+
+	if _, err := NextInstallStatus(status, RequestDiscard); err == nil {
+		discardResp, err := i.Client.Discard(ctx, &pb.DiscardRequest{Devid: devid})
+		if err != nil {
+			return err
+		}
+		return responseError(discardResp.ResponseCode, ErrInstallFailed)
+	}
+
+	// Nothing to roll back, e.g. install is already discarded on server.
+	return nil
+}
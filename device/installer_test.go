@@ -0,0 +1,199 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/fake"
+)
+
+func newTestInstaller(t *testing.T, dev *pb.Device) (*Installer, *fake.Server) {
+	cli, srv, _ := newTestClient(t, dev)
+
+	installer := NewInstaller(cli)
+	installer.PollInterval = 10 * time.Millisecond
+
+	return installer, srv
+}
+
+// reportAcceleration simulates status report of device which server received.
+func reportAcceleration(srv *fake.Server, devid string, x, y, z float64) {
+	reportAccelerationAt(srv, devid, time.Now(), x, y, z)
+}
+
+// reportAccelerationAt simulates status report which server received at time of its own clock.
+func reportAccelerationAt(srv *fake.Server, devid string, at time.Time, x, y, z float64) {
+	dev := srv.Device(devid)
+	dev.AccXMg, dev.AccYMg, dev.AccZMg = x, y, z
+	dev.UpdateDate, _ = ptypes.TimestampProto(at)
+	srv.AddDevice(dev)
+}
+
+// setInstallStatus simulates change of install status by others.
+func setInstallStatus(srv *fake.Server, devid string, status pb.InstallStatus) {
+	dev := srv.Device(devid)
+	dev.InstallStatus = status
+	srv.AddDevice(dev)
+}
+
+func TestNewInstaller(t *testing.T) {
+	installer := NewInstaller(nil)
+
+	assert.Equal(t, defaultInstallAckTimeout, installer.AckTimeout)
+	assert.Equal(t, defaultInstallBaselineTimeout, installer.BaselineTimeout)
+	assert.True(t, installer.AckTimeout > 0)
+	assert.True(t, installer.BaselineTimeout > 0)
+}
+
+func TestInstallerInstall(t *testing.T) {
+	testDevid := "ino-vibe-test"
+	installer, srv := newTestInstaller(t, &pb.Device{Devid: testDevid, InstallStatus: pb.InstallStatus_Initial})
+
+	stages := []InstallStage{}
+	installer.OnProgress = func(p InstallProgress) {
+		stages = append(stages, p.Stage)
+
+		switch p.Stage {
+		case StagePrepared:
+			// Device reports acceleration after install button is pressed.
+			assert.Equal(t, pb.InstallStatus_Requested, srv.Device(testDevid).InstallStatus)
+			reportAcceleration(srv, testDevid, 0, 0, 1000)
+		case StageAcknowledged:
+			assert.Equal(t, pb.InstallStatus_WaitInstallComplete, srv.Device(testDevid).InstallStatus)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	progress, err := installer.Install(ctx, &pb.PrepareInstallRequest{Devid: testDevid, Alias: "test-alias"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []InstallStage{StagePrepared, StageAcknowledged, StageBaselineCaptured, StageCompleted}, stages)
+	assert.Equal(t, StageCompleted, progress.Stage)
+	assert.NotEmpty(t, progress.InstallSessionKey)
+	assert.Equal(t, float64(1000), progress.Baseline.AccZMg)
+	assert.Equal(t, progress.InstallSessionKey, progress.Baseline.InstallSessionKey)
+
+	dev := srv.Device(testDevid)
+	assert.Equal(t, pb.InstallStatus_Installed, dev.InstallStatus)
+	assert.Equal(t, progress.InstallSessionKey, dev.InstallSessionKey)
+}
+
+func TestInstallerSetupNotice(t *testing.T) {
+	testDevid := "ino-vibe-test"
+	installer, srv := newTestInstaller(t, &pb.Device{Devid: testDevid, InstallStatus: pb.InstallStatus_Initial})
+
+	stages := []InstallStage{}
+	installer.OnProgress = func(p InstallProgress) {
+		stages = append(stages, p.Stage)
+
+		switch p.Stage {
+		case StagePrepared:
+			installer.NotifySetup(testDevid)
+		case StageAcknowledged:
+			assert.Equal(t, pb.InstallStatus_WaitInstallComplete, srv.Device(testDevid).InstallStatus)
+			assert.Nil(t, srv.Device(testDevid).UpdateDate)
+			reportAcceleration(srv, testDevid, 0, 500, 866)
+		}
+	}
+
+	// Notice of device which is not being installed is ignored.
+	installer.NotifySetup("other")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	progress, err := installer.Install(ctx, &pb.PrepareInstallRequest{Devid: testDevid})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []InstallStage{StagePrepared, StageAcknowledged, StageBaselineCaptured, StageCompleted}, stages)
+	assert.Equal(t, float64(866), progress.Baseline.AccZMg)
+	assert.Equal(t, pb.InstallStatus_Installed, srv.Device(testDevid).InstallStatus)
+	assert.False(t, installer.setupNotified(testDevid))
+	assert.False(t, installer.setupNotified("other"))
+}
+
+func TestInstallerAckTimeout(t *testing.T) {
+	testDevid := "ino-vibe-test"
+	installer, srv := newTestInstaller(t, &pb.Device{Devid: testDevid, InstallStatus: pb.InstallStatus_Initial})
+	installer.AckTimeout = 50 * time.Millisecond
+
+	_, err := installer.Install(context.Background(), &pb.PrepareInstallRequest{Devid: testDevid})
+
+	installErr, ok := err.(*InstallError)
+	assert.True(t, ok)
+	assert.Equal(t, StagePrepared, installErr.Stage)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Nil(t, installErr.RollbackErr)
+
+	assert.Equal(t, pb.InstallStatus_Discarded, srv.Device(testDevid).InstallStatus)
+}
+
+func TestInstallerRetry(t *testing.T) {
+	testDevid := "ino-vibe-test"
+
+	// Clock of server is ahead of client and device reported before install.
+	serverNow := time.Now().Add(time.Hour)
+	lastReport, _ := ptypes.TimestampProto(serverNow)
+	installer, srv := newTestInstaller(t, &pb.Device{Devid: testDevid, InstallStatus: pb.InstallStatus_Initial, UpdateDate: lastReport})
+	installer.AckTimeout = 50 * time.Millisecond
+
+	// Report before install is not acknowledgement.
+	_, err := installer.Install(context.Background(), &pb.PrepareInstallRequest{Devid: testDevid})
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, StagePrepared, err.(*InstallError).Stage)
+	assert.Nil(t, err.(*InstallError).RollbackErr)
+	assert.Equal(t, pb.InstallStatus_Discarded, srv.Device(testDevid).InstallStatus)
+
+	installer.OnProgress = func(p InstallProgress) {
+		if p.Stage == StagePrepared {
+			reportAccelerationAt(srv, testDevid, serverNow.Add(time.Minute), 0, 0, 1000)
+		}
+	}
+
+	progress, err := installer.Install(context.Background(), &pb.PrepareInstallRequest{Devid: testDevid})
+
+	assert.Nil(t, err)
+	assert.Equal(t, StageCompleted, progress.Stage)
+	assert.Equal(t, pb.InstallStatus_Installed, srv.Device(testDevid).InstallStatus)
+}
+
+func TestInstallerAborted(t *testing.T) {
+	testDevid := "ino-vibe-test"
+	installer, srv := newTestInstaller(t, &pb.Device{Devid: testDevid, InstallStatus: pb.InstallStatus_Initial})
+
+	installer.OnProgress = func(p InstallProgress) {
+		if p.Stage == StagePrepared {
+			setInstallStatus(srv, testDevid, pb.InstallStatus_Discarded)
+		}
+	}
+
+	_, err := installer.Install(context.Background(), &pb.PrepareInstallRequest{Devid: testDevid})
+
+	assert.True(t, errors.Is(err, ErrInstallAborted))
+	assert.Nil(t, err.(*InstallError).RollbackErr)
+	assert.Equal(t, pb.InstallStatus_Discarded, srv.Device(testDevid).InstallStatus)
+}
+
+func TestInstallerForbidden(t *testing.T) {
+	testDevid := "ino-vibe-test"
+	installer, srv := newTestInstaller(t, &pb.Device{Devid: testDevid, InstallStatus: pb.InstallStatus_Installed})
+
+	called := false
+	installer.OnProgress = func(InstallProgress) { called = true }
+
+	_, err := installer.Install(context.Background(), &pb.PrepareInstallRequest{Devid: testDevid})
+
+	assert.True(t, errors.Is(err, ErrForbiddenInstallStatus))
+	assert.Equal(t, StageNone, err.(*InstallError).Stage)
+	assert.False(t, called)
+	assert.Equal(t, pb.InstallStatus_Installed, srv.Device(testDevid).InstallStatus)
+}
@@ -0,0 +1,66 @@
+package device
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/api/iterator"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+// DeviceIterator yields devices of FilterList as server streams them.
+type DeviceIterator struct {
+	stream pb.DeviceService_FilterListClient
+	cancel context.CancelFunc
+	err    error
+}
+
+// Next returns next device.
+// iterator.Done returns after last device, and error of stream is returned on every call after failure.
+func (it *DeviceIterator) Next() (*pb.Device, error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+
+	dev, err := it.stream.Recv()
+	if err == io.EOF {
+		err = iterator.Done
+	}
+
+	if err != nil {
+		it.err = err
+		it.cancel()
+		return nil, err
+	}
+
+	return dev, nil
+}
+
+// Close stops stream. Next returns context.Canceled after Close.
+func (it *DeviceIterator) Close() {
+	if it.err == nil {
+		it.err = context.Canceled
+	}
+	it.cancel()
+}
+
+// FilterListIter streams devices of filter constraints.
+// Stream is stopped when ctx is canceled or iterator is closed.
+func (c *client) FilterListIter(ctx context.Context, f *pb.DeviceFilterListRequest) *DeviceIterator {
+	cli := c.getDeviceClient()
+
+	ctx, cancel := context.WithCancel(ctx)
+	it := &DeviceIterator{cancel: cancel}
+
+	stream, err := cli.FilterList(ctx, f)
+	if err != nil {
+		it.err = err
+		cancel()
+		return it
+	}
+
+	it.stream = stream
+
+	return it
+}
@@ -0,0 +1,98 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+// iterDevices returns count devices whose battery is index.
+func iterDevices(count int) []*pb.Device {
+	devs := make([]*pb.Device, count)
+	for i := range devs {
+		devs[i] = &pb.Device{Devid: fmt.Sprintf("dev-%02d", i), Battery: uint32(i)}
+	}
+	return devs
+}
+
+func TestFilterListIter(t *testing.T) {
+	cli, _, _ := newTestClient(t, iterDevices(10)...)
+
+	it := cli.FilterListIter(context.Background(), &pb.DeviceFilterListRequest{})
+	defer it.Close()
+
+	devids := []string{}
+	for {
+		dev, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+
+		assert.Nil(t, err)
+		devids = append(devids, dev.Devid)
+	}
+
+	assert.Len(t, devids, 10)
+	assert.Equal(t, "dev-00", devids[0])
+
+	_, err := it.Next()
+	assert.Equal(t, iterator.Done, err)
+}
+
+func TestFilterListIterClose(t *testing.T) {
+	cli, _, _ := newTestClient(t, iterDevices(10)...)
+
+	it := cli.FilterListIter(context.Background(), &pb.DeviceFilterListRequest{})
+
+	dev, err := it.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "dev-00", dev.Devid)
+
+	it.Close()
+
+	_, err = it.Next()
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestFilterListIterCanceled(t *testing.T) {
+	cli, _, _ := newTestClient(t, iterDevices(10)...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := cli.FilterListIter(ctx, &pb.DeviceFilterListRequest{})
+	defer it.Close()
+
+	cancel()
+
+	// Devices already buffered may arrive, but stream ends with cancellation.
+	var err error
+	for err == nil {
+		_, err = it.Next()
+	}
+
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
+func TestFilterListPartial(t *testing.T) {
+	cli, srv, _ := newTestClient(t, iterDevices(10)...)
+
+	streamErr := status.Error(codes.Unavailable, "connection lost")
+	srv.FailFilterList(4, streamErr)
+
+	devs, err := cli.FilterList(context.Background(), &pb.DeviceFilterListRequest{})
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Len(t, devs, 4)
+	assert.Equal(t, "dev-03", devs[3].Devid)
+
+	devs, err = Query().BatteryBelow(2).Run(context.Background(), cli)
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Len(t, devs, 2)
+}
@@ -0,0 +1,211 @@
+package device
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LogStore is storage of status and inclination logs of devices.
+type LogStore interface {
+	// StatusLogs returns status logs of install session within time range, latest first.
+	StatusLogs(ctx context.Context, devid, installSession string, timeFrom, timeTo time.Time, offset, limit int) ([]StatusLog, error)
+	PutStatusLog(ctx context.Context, log *StatusLog) error
+
+	// InclinationLogs returns inclination logs of install session within time range, latest first.
+	InclinationLogs(ctx context.Context, devid, installSession string, timeFrom, timeTo time.Time, offset, limit int) ([]InclinationLog, error)
+	// LastInclinationLog returns latest inclination log of device or ErrNoEntities.
+	LastInclinationLog(ctx context.Context, devid string) (*InclinationLog, error)
+	PutInclinationLog(ctx context.Context, log *InclinationLog) error
+
+	// SequenceLogs returns sequence logs of device within time range, latest first.
+	SequenceLogs(ctx context.Context, devid string, timeFrom, timeTo time.Time, offset, limit int) ([]SequenceLog, error)
+	PutSequenceLog(ctx context.Context, log *SequenceLog) error
+
+	// LastBaselineLog returns latest baseline log of device or ErrNoEntities.
+	LastBaselineLog(ctx context.Context, devid string) (*BaselineLog, error)
+	PutBaselineLog(ctx context.Context, log *BaselineLog) error
+}
+
+type memoryLogStore struct {
+	mu           sync.Mutex
+	statusLogs   []StatusLog
+	inclinations []InclinationLog
+	sequences    []SequenceLog
+	baselines    []BaselineLog
+}
+
+// NewMemoryLogStore creates LogStore which keeps logs in memory only.
+func NewMemoryLogStore() LogStore {
+	return &memoryLogStore{}
+}
+
+func (m *memoryLogStore) StatusLogs(ctx context.Context, devid, installSession string, timeFrom, timeTo time.Time, offset, limit int) ([]StatusLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	matched := make([]StatusLog, 0)
+	for _, l := range m.statusLogs {
+		if l.Devid != devid || l.InstallSessionKey != installSession {
+			continue
+		}
+
+		if l.Time.Before(timeFrom) || l.Time.After(timeTo) {
+			continue
+		}
+
+		matched = append(matched, l)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Time.After(matched[j].Time)
+	})
+
+	logs := make([]StatusLog, 0, limit)
+	for i := offset; i < len(matched) && len(logs) < limit; i++ {
+		logs = append(logs, matched[i])
+	}
+
+	return logs, nil
+}
+
+func (m *memoryLogStore) PutStatusLog(ctx context.Context, log *StatusLog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.statusLogs = append(m.statusLogs, *log)
+
+	return nil
+}
+
+func (m *memoryLogStore) InclinationLogs(ctx context.Context, devid, installSession string, timeFrom, timeTo time.Time, offset, limit int) ([]InclinationLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	matched := make([]InclinationLog, 0)
+	for _, l := range m.inclinations {
+		if l.Devid != devid || l.InstallSessionKey != installSession {
+			continue
+		}
+
+		if l.Time.Before(timeFrom) || l.Time.After(timeTo) {
+			continue
+		}
+
+		matched = append(matched, l)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Time.After(matched[j].Time)
+	})
+
+	logs := make([]InclinationLog, 0, limit)
+	for i := offset; i < len(matched) && len(logs) < limit; i++ {
+		logs = append(logs, matched[i])
+	}
+
+	return logs, nil
+}
+
+func (m *memoryLogStore) LastInclinationLog(ctx context.Context, devid string) (*InclinationLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var latest *InclinationLog
+	for i, l := range m.inclinations {
+		if l.Devid != devid {
+			continue
+		}
+
+		if latest == nil || !l.Time.Before(latest.Time) {
+			latest = &m.inclinations[i]
+		}
+	}
+
+	if latest == nil {
+		return nil, ErrNoEntities
+	}
+
+	found := *latest
+	return &found, nil
+}
+
+func (m *memoryLogStore) PutInclinationLog(ctx context.Context, log *InclinationLog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inclinations = append(m.inclinations, *log)
+
+	return nil
+}
+
+func (m *memoryLogStore) SequenceLogs(ctx context.Context, devid string, timeFrom, timeTo time.Time, offset, limit int) ([]SequenceLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	matched := make([]SequenceLog, 0)
+	for _, l := range m.sequences {
+		if l.Devid != devid {
+			continue
+		}
+
+		if l.Time.Before(timeFrom) || l.Time.After(timeTo) {
+			continue
+		}
+
+		matched = append(matched, l)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Time.After(matched[j].Time)
+	})
+
+	logs := make([]SequenceLog, 0, limit)
+	for i := offset; i < len(matched) && len(logs) < limit; i++ {
+		logs = append(logs, matched[i])
+	}
+
+	return logs, nil
+}
+
+func (m *memoryLogStore) PutSequenceLog(ctx context.Context, log *SequenceLog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sequences = append(m.sequences, *log)
+
+	return nil
+}
+
+func (m *memoryLogStore) LastBaselineLog(ctx context.Context, devid string) (*BaselineLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var latest *BaselineLog
+	for i, l := range m.baselines {
+		if l.Devid != devid {
+			continue
+		}
+
+		if latest == nil || !l.Time.Before(latest.Time) {
+			latest = &m.baselines[i]
+		}
+	}
+
+	if latest == nil {
+		return nil, ErrNoEntities
+	}
+
+	found := *latest
+	return &found, nil
+}
+
+func (m *memoryLogStore) PutBaselineLog(ctx context.Context, log *BaselineLog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.baselines = append(m.baselines, *log)
+
+	return nil
+}
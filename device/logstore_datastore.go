@@ -0,0 +1,192 @@
+package device
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	statusLogKind   = "DevStatusLog"
+	inclinationKind = "inclination-log"
+	sequenceLogKind = "DevSequenceLog"
+	baselineLogKind = "DevBaselineLog"
+)
+
+type datastoreLogStore struct {
+	dsClient *datastore.Client
+}
+
+// NewDatastoreLogStore creates LogStore on Google Cloud Datastore.
+func NewDatastoreLogStore(dsClient *datastore.Client) LogStore {
+	return &datastoreLogStore{dsClient: dsClient}
+}
+
+func (d *datastoreLogStore) StatusLogs(ctx context.Context, devid, installSession string, timeFrom, timeTo time.Time, offset, limit int) ([]StatusLog, error) {
+	q := datastore.NewQuery(statusLogKind).
+		Filter("Devid =", devid).
+		Filter("InstallSessionKey =", installSession).
+		Filter("Time >=", timeFrom).
+		Filter("Time <=", timeTo).
+		Order("-Time").
+		Offset(offset).
+		Limit(limit)
+
+	iter := d.dsClient.Run(ctx, q)
+
+	logs := make([]StatusLog, 0, limit)
+
+	for {
+		newLog := StatusLog{}
+		_, err := iter.Next(&newLog)
+		if err == iterator.Done {
+			break
+		}
+
+		if err, ok := err.(*datastore.ErrFieldMismatch); ok {
+			log.Println("StatusLog", err)
+		} else if err != nil {
+			return []StatusLog{}, err
+		}
+
+		logs = append(logs, newLog)
+	}
+
+	return logs, nil
+}
+
+func (d *datastoreLogStore) PutStatusLog(ctx context.Context, statusLog *StatusLog) error {
+	newKey := datastore.IncompleteKey(statusLogKind, nil)
+	_, err := d.dsClient.Put(ctx, newKey, statusLog)
+
+	return err
+}
+
+func (d *datastoreLogStore) InclinationLogs(ctx context.Context, devid, installSession string, timeFrom, timeTo time.Time, offset, limit int) ([]InclinationLog, error) {
+	q := datastore.NewQuery(inclinationKind).
+		Filter("devid =", devid).
+		Filter("install_session_key =", installSession).
+		Filter("time_created >=", timeFrom).
+		Filter("time_created <=", timeTo).
+		Order("-time_created").
+		Offset(offset).
+		Limit(limit)
+
+	iter := d.dsClient.Run(ctx, q)
+
+	logs := make([]InclinationLog, 0, limit)
+
+	for {
+		newLog := InclinationLog{}
+		_, err := iter.Next(&newLog)
+		if err == iterator.Done {
+			break
+		}
+
+		if err, ok := err.(*datastore.ErrFieldMismatch); ok {
+			log.Println("InclinationLog", err)
+		} else if err != nil {
+			return []InclinationLog{}, err
+		}
+
+		logs = append(logs, newLog)
+	}
+
+	return logs, nil
+}
+
+func (d *datastoreLogStore) LastInclinationLog(ctx context.Context, devid string) (*InclinationLog, error) {
+	q := datastore.NewQuery(inclinationKind).
+		Filter("devid =", devid).
+		Order("-time_created").
+		Limit(1)
+
+	iter := d.dsClient.Run(ctx, q)
+
+	latestInclination := InclinationLog{}
+	_, err := iter.Next(&latestInclination)
+	switch {
+	case err == iterator.Done:
+		return nil, ErrNoEntities
+	case err != nil:
+		return nil, err
+	}
+
+	return &latestInclination, nil
+}
+
+func (d *datastoreLogStore) PutInclinationLog(ctx context.Context, inclinationLog *InclinationLog) error {
+	newKey := datastore.IncompleteKey(inclinationKind, nil)
+	_, err := d.dsClient.Put(ctx, newKey, inclinationLog)
+
+	return err
+}
+
+func (d *datastoreLogStore) SequenceLogs(ctx context.Context, devid string, timeFrom, timeTo time.Time, offset, limit int) ([]SequenceLog, error) {
+	q := datastore.NewQuery(sequenceLogKind).
+		Filter("Devid =", devid).
+		Filter("Time >=", timeFrom).
+		Filter("Time <=", timeTo).
+		Order("-Time").
+		Offset(offset).
+		Limit(limit)
+
+	iter := d.dsClient.Run(ctx, q)
+
+	logs := make([]SequenceLog, 0, limit)
+
+	for {
+		newLog := SequenceLog{}
+		_, err := iter.Next(&newLog)
+		if err == iterator.Done {
+			break
+		}
+
+		if err, ok := err.(*datastore.ErrFieldMismatch); ok {
+			log.Println("SequenceLog", err)
+		} else if err != nil {
+			return []SequenceLog{}, err
+		}
+
+		logs = append(logs, newLog)
+	}
+
+	return logs, nil
+}
+
+func (d *datastoreLogStore) PutSequenceLog(ctx context.Context, sequenceLog *SequenceLog) error {
+	newKey := datastore.IncompleteKey(sequenceLogKind, nil)
+	_, err := d.dsClient.Put(ctx, newKey, sequenceLog)
+
+	return err
+}
+
+func (d *datastoreLogStore) LastBaselineLog(ctx context.Context, devid string) (*BaselineLog, error) {
+	q := datastore.NewQuery(baselineLogKind).
+		Filter("Devid =", devid).
+		Order("-Time").
+		Limit(1)
+
+	iter := d.dsClient.Run(ctx, q)
+
+	latest := BaselineLog{}
+	_, err := iter.Next(&latest)
+	switch {
+	case err == iterator.Done:
+		return nil, ErrNoEntities
+	case err != nil:
+		return nil, err
+	}
+
+	return &latest, nil
+}
+
+func (d *datastoreLogStore) PutBaselineLog(ctx context.Context, baselineLog *BaselineLog) error {
+	newKey := datastore.IncompleteKey(baselineLogKind, nil)
+	_, err := d.dsClient.Put(ctx, newKey, baselineLog)
+
+	return err
+}
@@ -0,0 +1,282 @@
+package device
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS status_log (
+		devid TEXT NOT NULL,
+		time INTEGER NOT NULL,
+		temperature INTEGER NOT NULL,
+		battery INTEGER NOT NULL,
+		rssi INTEGER NOT NULL,
+		lora_err INTEGER NOT NULL DEFAULT 0,
+		install_session_key TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS status_log_session ON status_log (devid, install_session_key, time)`,
+	`CREATE TABLE IF NOT EXISTS inclination_log (
+		devid TEXT NOT NULL,
+		time INTEGER NOT NULL,
+		acc_x_mg REAL NOT NULL,
+		acc_y_mg REAL NOT NULL,
+		acc_z_mg REAL NOT NULL,
+		angle_z REAL NOT NULL,
+		pitch REAL NOT NULL DEFAULT 0,
+		roll REAL NOT NULL DEFAULT 0,
+		tilt REAL NOT NULL DEFAULT 0,
+		install_session_key TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS inclination_log_devid ON inclination_log (devid, time)`,
+	`CREATE INDEX IF NOT EXISTS inclination_log_session ON inclination_log (devid, install_session_key, time)`,
+	`CREATE TABLE IF NOT EXISTS sequence_log (
+		devid TEXT NOT NULL,
+		time INTEGER NOT NULL,
+		seq INTEGER NOT NULL,
+		missed INTEGER NOT NULL,
+		duplicate INTEGER NOT NULL,
+		late INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS sequence_log_devid ON sequence_log (devid, time)`,
+	`CREATE TABLE IF NOT EXISTS baseline_log (
+		devid TEXT NOT NULL,
+		time INTEGER NOT NULL,
+		x REAL NOT NULL,
+		y REAL NOT NULL,
+		z REAL NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS baseline_log_devid ON baseline_log (devid, time)`,
+}
+
+// sqliteAddedColumns are columns which are added after table was created first.
+// They are added to tables of existing database on open.
+var sqliteAddedColumns = []struct {
+	table      string
+	column     string
+	definition string
+}{
+	{"status_log", "lora_err", "INTEGER NOT NULL DEFAULT 0"},
+	{"inclination_log", "pitch", "REAL NOT NULL DEFAULT 0"},
+	{"inclination_log", "roll", "REAL NOT NULL DEFAULT 0"},
+	{"inclination_log", "tilt", "REAL NOT NULL DEFAULT 0"},
+}
+
+type sqliteLogStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteLogStore creates LogStore on SQLite database and creates tables if not exist.
+// Columns which tables of older version lack are added.
+// Driver of database should be registered by caller, e.g. github.com/mattn/go-sqlite3.
+func NewSQLiteLogStore(ctx context.Context, db *sql.DB) (LogStore, error) {
+	for _, stmt := range sqliteSchema {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, added := range sqliteAddedColumns {
+		if err := ensureColumn(ctx, db, added.table, added.column, added.definition); err != nil {
+			return nil, err
+		}
+	}
+
+	return &sqliteLogStore{db: db}, nil
+}
+
+// ensureColumn adds column to table if it does not exist.
+func ensureColumn(ctx context.Context, db *sql.DB, table, column, definition string) error {
+	var count int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, `ALTER TABLE `+table+` ADD COLUMN `+column+` `+definition)
+
+	return err
+}
+
+func (s *sqliteLogStore) StatusLogs(ctx context.Context, devid, installSession string, timeFrom, timeTo time.Time, offset, limit int) ([]StatusLog, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT devid, time, temperature, battery, rssi, lora_err, install_session_key FROM status_log
+		WHERE devid = ? AND install_session_key = ? AND time >= ? AND time <= ?
+		ORDER BY time DESC LIMIT ? OFFSET ?`,
+		devid, installSession, timeFrom.UnixNano(), timeTo.UnixNano(), limit, offset)
+	if err != nil {
+		return []StatusLog{}, err
+	}
+	defer rows.Close()
+
+	logs := make([]StatusLog, 0, limit)
+	for rows.Next() {
+		var (
+			newLog StatusLog
+			nsec   int64
+		)
+
+		err := rows.Scan(&newLog.Devid, &nsec, &newLog.Temperature, &newLog.Battery, &newLog.RSSI, &newLog.LoRaErr, &newLog.InstallSessionKey)
+		if err != nil {
+			return []StatusLog{}, err
+		}
+
+		newLog.Time = time.Unix(0, nsec)
+		logs = append(logs, newLog)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []StatusLog{}, err
+	}
+
+	return logs, nil
+}
+
+func (s *sqliteLogStore) PutStatusLog(ctx context.Context, log *StatusLog) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO status_log (devid, time, temperature, battery, rssi, lora_err, install_session_key) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		log.Devid, log.Time.UnixNano(), log.Temperature, log.Battery, log.RSSI, log.LoRaErr, log.InstallSessionKey)
+
+	return err
+}
+
+func (s *sqliteLogStore) InclinationLogs(ctx context.Context, devid, installSession string, timeFrom, timeTo time.Time, offset, limit int) ([]InclinationLog, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT devid, time, acc_x_mg, acc_y_mg, acc_z_mg, angle_z, pitch, roll, tilt, install_session_key FROM inclination_log
+		WHERE devid = ? AND install_session_key = ? AND time >= ? AND time <= ?
+		ORDER BY time DESC LIMIT ? OFFSET ?`,
+		devid, installSession, timeFrom.UnixNano(), timeTo.UnixNano(), limit, offset)
+	if err != nil {
+		return []InclinationLog{}, err
+	}
+	defer rows.Close()
+
+	logs := make([]InclinationLog, 0, limit)
+	for rows.Next() {
+		var (
+			newLog InclinationLog
+			nsec   int64
+		)
+
+		err := rows.Scan(&newLog.Devid, &nsec, &newLog.AccXMg, &newLog.AccYMg, &newLog.AccZMg, &newLog.AngleZ, &newLog.Pitch, &newLog.Roll, &newLog.Tilt, &newLog.InstallSessionKey)
+		if err != nil {
+			return []InclinationLog{}, err
+		}
+
+		newLog.Time = time.Unix(0, nsec)
+		logs = append(logs, newLog)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []InclinationLog{}, err
+	}
+
+	return logs, nil
+}
+
+func (s *sqliteLogStore) LastInclinationLog(ctx context.Context, devid string) (*InclinationLog, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT devid, time, acc_x_mg, acc_y_mg, acc_z_mg, angle_z, pitch, roll, tilt, install_session_key FROM inclination_log
+		WHERE devid = ? ORDER BY time DESC LIMIT 1`,
+		devid)
+
+	var (
+		latest InclinationLog
+		nsec   int64
+	)
+
+	err := row.Scan(&latest.Devid, &nsec, &latest.AccXMg, &latest.AccYMg, &latest.AccZMg, &latest.AngleZ, &latest.Pitch, &latest.Roll, &latest.Tilt, &latest.InstallSessionKey)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, ErrNoEntities
+	case err != nil:
+		return nil, err
+	}
+
+	latest.Time = time.Unix(0, nsec)
+
+	return &latest, nil
+}
+
+func (s *sqliteLogStore) PutInclinationLog(ctx context.Context, log *InclinationLog) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO inclination_log (devid, time, acc_x_mg, acc_y_mg, acc_z_mg, angle_z, pitch, roll, tilt, install_session_key) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		log.Devid, log.Time.UnixNano(), log.AccXMg, log.AccYMg, log.AccZMg, log.AngleZ, log.Pitch, log.Roll, log.Tilt, log.InstallSessionKey)
+
+	return err
+}
+
+func (s *sqliteLogStore) SequenceLogs(ctx context.Context, devid string, timeFrom, timeTo time.Time, offset, limit int) ([]SequenceLog, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT devid, time, seq, missed, duplicate, late FROM sequence_log
+		WHERE devid = ? AND time >= ? AND time <= ?
+		ORDER BY time DESC LIMIT ? OFFSET ?`,
+		devid, timeFrom.UnixNano(), timeTo.UnixNano(), limit, offset)
+	if err != nil {
+		return []SequenceLog{}, err
+	}
+	defer rows.Close()
+
+	logs := make([]SequenceLog, 0, limit)
+	for rows.Next() {
+		var (
+			newLog SequenceLog
+			nsec   int64
+		)
+
+		err := rows.Scan(&newLog.Devid, &nsec, &newLog.Seq, &newLog.Missed, &newLog.Duplicate, &newLog.Late)
+		if err != nil {
+			return []SequenceLog{}, err
+		}
+
+		newLog.Time = time.Unix(0, nsec)
+		logs = append(logs, newLog)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []SequenceLog{}, err
+	}
+
+	return logs, nil
+}
+
+func (s *sqliteLogStore) PutSequenceLog(ctx context.Context, log *SequenceLog) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO sequence_log (devid, time, seq, missed, duplicate, late) VALUES (?, ?, ?, ?, ?, ?)`,
+		log.Devid, log.Time.UnixNano(), log.Seq, log.Missed, log.Duplicate, log.Late)
+
+	return err
+}
+
+func (s *sqliteLogStore) LastBaselineLog(ctx context.Context, devid string) (*BaselineLog, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT devid, time, x, y, z FROM baseline_log
+		WHERE devid = ? ORDER BY time DESC LIMIT 1`,
+		devid)
+
+	var (
+		latest BaselineLog
+		nsec   int64
+	)
+
+	err := row.Scan(&latest.Devid, &nsec, &latest.X, &latest.Y, &latest.Z)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, ErrNoEntities
+	case err != nil:
+		return nil, err
+	}
+
+	latest.Time = time.Unix(0, nsec)
+
+	return &latest, nil
+}
+
+func (s *sqliteLogStore) PutBaselineLog(ctx context.Context, log *BaselineLog) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO baseline_log (devid, time, x, y, z) VALUES (?, ?, ?, ?, ?)`,
+		log.Devid, log.Time.UnixNano(), log.X, log.Y, log.Z)
+
+	return err
+}
@@ -0,0 +1,225 @@
+package device
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+func newTestLogStores(t *testing.T) map[string]LogStore {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	sqliteStore, err := NewSQLiteLogStore(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]LogStore{
+		"memory": NewMemoryLogStore(),
+		"sqlite": sqliteStore,
+	}
+}
+
+func TestSQLiteLogStoreMigration(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	// Tables of first version.
+	for _, stmt := range []string{
+		`CREATE TABLE status_log (devid TEXT NOT NULL, time INTEGER NOT NULL, temperature INTEGER NOT NULL,
+			battery INTEGER NOT NULL, rssi INTEGER NOT NULL, install_session_key TEXT NOT NULL)`,
+		`CREATE TABLE inclination_log (devid TEXT NOT NULL, time INTEGER NOT NULL, acc_x_mg REAL NOT NULL,
+			acc_y_mg REAL NOT NULL, acc_z_mg REAL NOT NULL, angle_z REAL NOT NULL, install_session_key TEXT NOT NULL)`,
+		`INSERT INTO status_log VALUES ('dev-1', 1, 20, 90, -80, 's1')`,
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, err := NewSQLiteLogStore(ctx, db)
+	assert.Nil(t, err)
+
+	// Opening migrated database again does nothing.
+	store, err = NewSQLiteLogStore(ctx, db)
+	assert.Nil(t, err)
+
+	now := time.Now()
+	assert.Nil(t, store.PutStatusLog(ctx, &StatusLog{Devid: "dev-1", Time: now, Battery: 80, LoRaErr: 2, InstallSessionKey: "s1"}))
+	assert.Nil(t, store.PutInclinationLog(ctx, &InclinationLog{Devid: "dev-1", Time: now, Pitch: 1, Roll: 2, Tilt: 3, InstallSessionKey: "s1"}))
+
+	logs, err := store.StatusLogs(ctx, "dev-1", "s1", time.Unix(0, 0), now, 0, 10)
+	assert.Nil(t, err)
+	assert.Len(t, logs, 2)
+	assert.Equal(t, 2, logs[0].LoRaErr)
+	assert.Equal(t, 0, logs[1].LoRaErr)
+
+	latest, err := store.LastInclinationLog(ctx, "dev-1")
+	assert.Nil(t, err)
+	assert.Equal(t, float64(3), latest.Tilt)
+}
+
+func TestLogStoreStatusLogs(t *testing.T) {
+	ctx := context.Background()
+	base := time.Unix(1600000000, 0)
+
+	for name, store := range newTestLogStores(t) {
+		for i := 0; i < 5; i++ {
+			_ = store.PutStatusLog(ctx, &StatusLog{
+				Devid:             "dev-1",
+				Time:              base.Add(time.Duration(i) * time.Minute),
+				Battery:           100 - i,
+				LoRaErr:           i,
+				InstallSessionKey: "session-1",
+			})
+		}
+		_ = store.PutStatusLog(ctx, &StatusLog{Devid: "dev-1", Time: base, InstallSessionKey: "session-0"})
+		_ = store.PutStatusLog(ctx, &StatusLog{Devid: "dev-2", Time: base, InstallSessionKey: "session-1"})
+
+		logs, err := store.StatusLogs(ctx, "dev-1", "session-1", base.Add(time.Minute), base.Add(3*time.Minute), 0, 10)
+		assert.Nil(t, err, name)
+		assert.Len(t, logs, 3, name)
+		assert.Equal(t, 97, logs[0].Battery, name)
+		assert.Equal(t, 3, logs[0].LoRaErr, name)
+		assert.Equal(t, 99, logs[2].Battery, name)
+		assert.True(t, logs[0].Time.Equal(base.Add(3*time.Minute)), name)
+
+		logs, err = store.StatusLogs(ctx, "dev-1", "session-1", base, base.Add(time.Hour), 1, 2)
+		assert.Nil(t, err, name)
+		assert.Len(t, logs, 2, name)
+		assert.Equal(t, 97, logs[0].Battery, name)
+		assert.Equal(t, 98, logs[1].Battery, name)
+	}
+}
+
+func TestLogStoreLastInclinationLog(t *testing.T) {
+	ctx := context.Background()
+	base := time.Unix(1600000000, 0)
+
+	for name, store := range newTestLogStores(t) {
+		_, err := store.LastInclinationLog(ctx, "dev-1")
+		assert.Equal(t, ErrNoEntities, err, name)
+
+		_ = store.PutInclinationLog(ctx, &InclinationLog{Devid: "dev-1", Time: base.Add(time.Minute), AngleZ: 2, InstallSessionKey: "session-1"})
+		_ = store.PutInclinationLog(ctx, &InclinationLog{Devid: "dev-1", Time: base, AngleZ: 1, InstallSessionKey: "session-1"})
+		_ = store.PutInclinationLog(ctx, &InclinationLog{Devid: "dev-2", Time: base.Add(time.Hour), AngleZ: 3})
+
+		latest, err := store.LastInclinationLog(ctx, "dev-1")
+		assert.Nil(t, err, name)
+		assert.Equal(t, float64(2), latest.AngleZ, name)
+		assert.Equal(t, "session-1", latest.InstallSessionKey, name)
+		assert.True(t, latest.Time.Equal(base.Add(time.Minute)), name)
+	}
+}
+
+func TestLogStoreLastBaselineLog(t *testing.T) {
+	ctx := context.Background()
+	base := time.Unix(1600000000, 0)
+
+	for name, store := range newTestLogStores(t) {
+		_, err := store.LastBaselineLog(ctx, "dev-1")
+		assert.Equal(t, ErrNoEntities, err, name)
+
+		_ = store.PutBaselineLog(ctx, &BaselineLog{Devid: "dev-1", Time: base.Add(time.Minute), Z: 1000})
+		_ = store.PutBaselineLog(ctx, &BaselineLog{Devid: "dev-1", Time: base, X: 1000})
+		_ = store.PutBaselineLog(ctx, &BaselineLog{Devid: "dev-2", Time: base.Add(time.Hour), Y: 1000})
+
+		latest, err := store.LastBaselineLog(ctx, "dev-1")
+		assert.Nil(t, err, name)
+		assert.Equal(t, float64(1000), latest.Z, name)
+		assert.Equal(t, float64(0), latest.X, name)
+		assert.True(t, latest.Time.Equal(base.Add(time.Minute)), name)
+	}
+}
+
+func TestLogStoreInclinationLogs(t *testing.T) {
+	ctx := context.Background()
+	base := time.Unix(1600000000, 0)
+
+	for name, store := range newTestLogStores(t) {
+		for i := 0; i < 5; i++ {
+			_ = store.PutInclinationLog(ctx, &InclinationLog{
+				Devid:             "dev-1",
+				Time:              base.Add(time.Duration(i) * time.Minute),
+				AngleZ:            float64(i),
+				InstallSessionKey: "session-1",
+			})
+		}
+		_ = store.PutInclinationLog(ctx, &InclinationLog{Devid: "dev-1", Time: base, InstallSessionKey: "session-0"})
+		_ = store.PutInclinationLog(ctx, &InclinationLog{Devid: "dev-2", Time: base, InstallSessionKey: "session-1"})
+
+		logs, err := store.InclinationLogs(ctx, "dev-1", "session-1", base.Add(time.Minute), base.Add(3*time.Minute), 0, 10)
+		assert.Nil(t, err, name)
+		assert.Len(t, logs, 3, name)
+		assert.Equal(t, float64(3), logs[0].AngleZ, name)
+		assert.Equal(t, float64(1), logs[2].AngleZ, name)
+
+		logs, err = store.InclinationLogs(ctx, "dev-1", "session-1", base, base.Add(time.Hour), 1, 2)
+		assert.Nil(t, err, name)
+		assert.Len(t, logs, 2, name)
+		assert.Equal(t, float64(3), logs[0].AngleZ, name)
+		assert.Equal(t, float64(2), logs[1].AngleZ, name)
+	}
+}
+
+func TestClientWithLogStore(t *testing.T) {
+	cli, srv, _ := newTestClient(t,
+		&pb.Device{Devid: "installed", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "session-1"},
+		&pb.Device{Devid: "initial", InstallStatus: pb.InstallStatus_Initial},
+	)
+	ctx := context.Background()
+
+	assert.Equal(t, ErrForbiddenInstallStatus, cli.StoreStatusLog(ctx, "initial", 100, 20, -80))
+	assert.Equal(t, ErrNonExistDevice, cli.StoreStatusLog(ctx, "unknown", 100, 20, -80))
+	assert.Nil(t, cli.StoreStatusLog(ctx, "installed", 90, 25, -70))
+
+	logs, err := cli.StatusLog(ctx, "installed", "session-1", time.Now().Add(-time.Minute), time.Now(), 0, 10)
+	assert.Nil(t, err)
+	assert.Len(t, logs, 1)
+	assert.Equal(t, 90, logs[0].Battery)
+
+	_, err = cli.LastInclinationLog(ctx, "installed")
+	assert.Equal(t, ErrNoEntities, err)
+
+	_, err = cli.StoreInclinationLog(ctx, "installed", 10, 10, 1000)
+	assert.Nil(t, err)
+
+	latest, err := cli.LastInclinationLog(ctx, "installed")
+	assert.Nil(t, err)
+	assert.Equal(t, "session-1", latest.InstallSessionKey)
+
+	_, err = cli.StoreInclinationLog(ctx, "installed", 0, 0, 0)
+	assert.Equal(t, ErrInvalidInclinationValue, err)
+
+	calibrated, err := cli.StoreCalibratedInclinationLog(ctx, "installed", 0, 1000, 0, Acceleration{Y: 1000})
+	assert.Nil(t, err)
+	assert.Equal(t, float64(0), calibrated.Tilt)
+	assert.Equal(t, float64(0), calibrated.AngleZ)
+
+	latest, err = cli.LastInclinationLog(ctx, "installed")
+	assert.Nil(t, err)
+	assert.Equal(t, float64(244), latest.AccYMg)
+
+	// Log of previous install session is ignored.
+	srv.AddDevice(&pb.Device{Devid: "installed", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "session-2"})
+
+	_, err = cli.LastInclinationLog(ctx, "installed")
+	assert.Equal(t, ErrNoEntities, err)
+}
@@ -16,6 +16,54 @@ type MockClient struct {
 	mock.Mock
 }
 
+// BatchUpdateConfig provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockClient) BatchUpdateConfig(_a0 context.Context, _a1 []*inovibe_api_v3.DeviceConfigUpdateRequest, _a2 BatchOptions) BatchResults {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 BatchResults
+	if rf, ok := ret.Get(0).(func(context.Context, []*inovibe_api_v3.DeviceConfigUpdateRequest, BatchOptions) BatchResults); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(BatchResults)
+		}
+	}
+
+	return r0
+}
+
+// BatchUpdateInfo provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockClient) BatchUpdateInfo(_a0 context.Context, _a1 []*inovibe_api_v3.DeviceInfoUpdateRequest, _a2 BatchOptions) BatchResults {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 BatchResults
+	if rf, ok := ret.Get(0).(func(context.Context, []*inovibe_api_v3.DeviceInfoUpdateRequest, BatchOptions) BatchResults); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(BatchResults)
+		}
+	}
+
+	return r0
+}
+
+// BatchUpdateStatus provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockClient) BatchUpdateStatus(_a0 context.Context, _a1 []*inovibe_api_v3.DeviceStatusUpdateRequest, _a2 BatchOptions) BatchResults {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 BatchResults
+	if rf, ok := ret.Get(0).(func(context.Context, []*inovibe_api_v3.DeviceStatusUpdateRequest, BatchOptions) BatchResults); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(BatchResults)
+		}
+	}
+
+	return r0
+}
+
 // CompleteInstall provides a mock function with given fields: _a0, _a1
 func (_m *MockClient) CompleteInstall(_a0 context.Context, _a1 *inovibe_api_v3.CompleteInstallRequest) (*inovibe_api_v3.CompleteInstallResponse, error) {
 	ret := _m.Called(_a0, _a1)
@@ -108,6 +156,68 @@ func (_m *MockClient) FilterList(_a0 context.Context, _a1 *inovibe_api_v3.Device
 	return r0, r1
 }
 
+// FilterListIter provides a mock function with given fields: _a0, _a1
+func (_m *MockClient) FilterListIter(_a0 context.Context, _a1 *inovibe_api_v3.DeviceFilterListRequest) *DeviceIterator {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *DeviceIterator
+	if rf, ok := ret.Get(0).(func(context.Context, *inovibe_api_v3.DeviceFilterListRequest) *DeviceIterator); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeviceIterator)
+		}
+	}
+
+	return r0
+}
+
+// InclinationLogs provides a mock function with given fields: ctx, devid, installKey, timeFrom, timeTo, offset, limit
+func (_m *MockClient) InclinationLogs(ctx context.Context, devid string, installKey string, timeFrom time.Time, timeTo time.Time, offset int, limit int) ([]InclinationLog, error) {
+	ret := _m.Called(ctx, devid, installKey, timeFrom, timeTo, offset, limit)
+
+	var r0 []InclinationLog
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time, time.Time, int, int) []InclinationLog); ok {
+		r0 = rf(ctx, devid, installKey, timeFrom, timeTo, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]InclinationLog)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time, time.Time, int, int) error); ok {
+		r1 = rf(ctx, devid, installKey, timeFrom, timeTo, offset, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Ingest provides a mock function with given fields: ctx, devid, rawHex
+func (_m *MockClient) Ingest(ctx context.Context, devid string, rawHex string) (*IngestResult, error) {
+	ret := _m.Called(ctx, devid, rawHex)
+
+	var r0 *IngestResult
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *IngestResult); ok {
+		r0 = rf(ctx, devid, rawHex)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*IngestResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, devid, rawHex)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // LastInclinationLog provides a mock function with given fields: _a0, _a1
 func (_m *MockClient) LastInclinationLog(_a0 context.Context, _a1 string) (*InclinationLog, error) {
 	ret := _m.Called(_a0, _a1)
@@ -154,6 +264,29 @@ func (_m *MockClient) List(_a0 context.Context, _a1 inovibe_api_v3.InstallStatus
 	return r0, r1
 }
 
+// PacketStats provides a mock function with given fields: ctx, devid, timeFrom, timeTo
+func (_m *MockClient) PacketStats(ctx context.Context, devid string, timeFrom time.Time, timeTo time.Time) (*PacketStats, error) {
+	ret := _m.Called(ctx, devid, timeFrom, timeTo)
+
+	var r0 *PacketStats
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) *PacketStats); ok {
+		r0 = rf(ctx, devid, timeFrom, timeTo)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*PacketStats)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, devid, timeFrom, timeTo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PrepareInstall provides a mock function with given fields: _a0, _a1
 func (_m *MockClient) PrepareInstall(_a0 context.Context, _a1 *inovibe_api_v3.PrepareInstallRequest) (*inovibe_api_v3.PrepareInstallResponse, error) {
 	ret := _m.Called(_a0, _a1)
@@ -200,6 +333,29 @@ func (_m *MockClient) StatusLog(ctx context.Context, devid string, installKey st
 	return r0, r1
 }
 
+// StoreCalibratedInclinationLog provides a mock function with given fields: ctx, devid, rawX, rawY, rawZ, baseline
+func (_m *MockClient) StoreCalibratedInclinationLog(ctx context.Context, devid string, rawX int, rawY int, rawZ int, baseline Acceleration) (*InclinationLog, error) {
+	ret := _m.Called(ctx, devid, rawX, rawY, rawZ, baseline)
+
+	var r0 *InclinationLog
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int, int, Acceleration) *InclinationLog); ok {
+		r0 = rf(ctx, devid, rawX, rawY, rawZ, baseline)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*InclinationLog)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int, int, Acceleration) error); ok {
+		r1 = rf(ctx, devid, rawX, rawY, rawZ, baseline)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // StoreInclinationLog provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
 func (_m *MockClient) StoreInclinationLog(_a0 context.Context, _a1 string, _a2 int, _a3 int, _a4 int) (float64, error) {
 	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
@@ -372,3 +528,19 @@ func (_m *MockClient) WaitCompleteInstall(_a0 context.Context, _a1 *inovibe_api_
 
 	return r0, r1
 }
+
+// Watch provides a mock function with given fields: ctx, filter, interval
+func (_m *MockClient) Watch(ctx context.Context, filter Filter, interval time.Duration) <-chan WatchEvent {
+	ret := _m.Called(ctx, filter, interval)
+
+	var r0 <-chan WatchEvent
+	if rf, ok := ret.Get(0).(func(context.Context, Filter, time.Duration) <-chan WatchEvent); ok {
+		r0 = rf(ctx, filter, interval)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan WatchEvent)
+		}
+	}
+
+	return r0
+}
@@ -0,0 +1,121 @@
+package device
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/api/iterator"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+// DeviceQuery builds FilterList request and filters which server does not support.
+//
+//	devs, err := device.Query().
+//		InGroup(groupID).
+//		WithStatus(pb.InstallStatus_Installed).
+//		BatteryBelow(20).
+//		Run(ctx, cli)
+type DeviceQuery struct {
+	filter  Filter
+	matches []func(*pb.Device) bool
+}
+
+// Query creates empty query which matches every device.
+func Query() *DeviceQuery {
+	return &DeviceQuery{}
+}
+
+// InGroup selects devices of group.
+func (q *DeviceQuery) InGroup(groupID string) *DeviceQuery {
+	q.filter.GroupID = FilterGroupID{Value: groupID}
+	return q
+}
+
+// WithStatus selects devices of install status.
+func (q *DeviceQuery) WithStatus(status pb.InstallStatus) *DeviceQuery {
+	q.filter.InstallStatus = FilterInstallStatus{Value: status}
+	return q
+}
+
+// Alias selects devices whose alias contains text, ignoring case.
+func (q *DeviceQuery) Alias(text string) *DeviceQuery {
+	text = strings.ToLower(text)
+	return q.Where(func(dev *pb.Device) bool {
+		return strings.Contains(strings.ToLower(dev.Alias), text)
+	})
+}
+
+// BatteryBelow selects devices whose battery is less than percent.
+func (q *DeviceQuery) BatteryBelow(percent uint32) *DeviceQuery {
+	return q.Where(func(dev *pb.Device) bool {
+		return dev.Battery < percent
+	})
+}
+
+// LastSeenBefore selects devices which did not report since t.
+// Device which never reported is also selected.
+func (q *DeviceQuery) LastSeenBefore(t time.Time) *DeviceQuery {
+	return q.Where(func(dev *pb.Device) bool {
+		updated, err := ptypes.Timestamp(dev.UpdateDate)
+		return err != nil || updated.Before(t)
+	})
+}
+
+// FirmwareVersion selects devices running application firmware of version.
+func (q *DeviceQuery) FirmwareVersion(version string) *DeviceQuery {
+	return q.Where(func(dev *pb.Device) bool {
+		return dev.AppFwVer == version
+	})
+}
+
+// Where adds custom client-side filter.
+func (q *DeviceQuery) Where(match func(*pb.Device) bool) *DeviceQuery {
+	q.matches = append(q.matches, match)
+	return q
+}
+
+// Filter returns server-side constraints of query.
+func (q *DeviceQuery) Filter() Filter {
+	return q.filter
+}
+
+// Request returns FilterList request of query.
+func (q *DeviceQuery) Request() *pb.DeviceFilterListRequest {
+	return q.filter.Request()
+}
+
+// Match reports device passes every client-side filter.
+func (q *DeviceQuery) Match(dev *pb.Device) bool {
+	for _, match := range q.matches {
+		if !match(dev) {
+			return false
+		}
+	}
+	return true
+}
+
+// Run requests devices by FilterList and returns ones which match query.
+// Devices matched before stream fails are returned with the error.
+func (q *DeviceQuery) Run(ctx context.Context, cli Client) ([]*pb.Device, error) {
+	it := cli.FilterListIter(ctx, q.Request())
+	defer it.Close()
+
+	matched := make([]*pb.Device, 0)
+	for {
+		dev, err := it.Next()
+		if err == iterator.Done {
+			return matched, nil
+		}
+
+		if err != nil {
+			return matched, err
+		}
+
+		if q.Match(dev) {
+			matched = append(matched, dev)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package device
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+func TestFilterRequest(t *testing.T) {
+	assert.Equal(t, &pb.DeviceFilterListRequest{}, Filter{}.Request())
+
+	f := Filter{
+		InstallStatus: FilterInstallStatus{Value: pb.InstallStatus_Installed},
+		GroupID:       FilterGroupID{Value: "group-1"},
+	}
+	assert.Equal(t, &pb.DeviceFilterListRequest{
+		InstallStatus: &pb.DeviceFilterListRequest_InstallStatusValue{InstallStatusValue: pb.InstallStatus_Installed},
+		GroupId:       &pb.DeviceFilterListRequest_GroupIdValue{GroupIdValue: "group-1"},
+	}, f.Request())
+}
+
+func TestQueryRequest(t *testing.T) {
+	q := Query().InGroup("group-1").WithStatus(pb.InstallStatus_Requested).Alias("gate")
+
+	assert.Equal(t, Filter{
+		InstallStatus: FilterInstallStatus{Value: pb.InstallStatus_Requested},
+		GroupID:       FilterGroupID{Value: "group-1"},
+	}, q.Filter())
+	assert.Equal(t, "group-1", q.Request().GetGroupIdValue())
+	assert.Equal(t, pb.InstallStatus_Requested, q.Request().GetInstallStatusValue())
+}
+
+func TestQueryMatch(t *testing.T) {
+	now := time.Now()
+	dev := &pb.Device{
+		Alias:      "North Gate 1",
+		Battery:    15,
+		AppFwVer:   "1.2.0",
+		UpdateDate: &timestamp.Timestamp{Seconds: now.Add(-2 * time.Hour).Unix()},
+	}
+
+	assert.True(t, Query().Match(dev))
+	assert.True(t, Query().Alias("gate").BatteryBelow(20).FirmwareVersion("1.2.0").Match(dev))
+	assert.True(t, Query().LastSeenBefore(now.Add(-time.Hour)).Match(dev))
+	assert.True(t, Query().LastSeenBefore(now).Match(&pb.Device{}))
+
+	assert.False(t, Query().Alias("south").Match(dev))
+	assert.False(t, Query().BatteryBelow(15).Match(dev))
+	assert.False(t, Query().FirmwareVersion("1.3.0").Match(dev))
+	assert.False(t, Query().LastSeenBefore(now.Add(-3*time.Hour)).Match(dev))
+	assert.False(t, Query().Where(func(*pb.Device) bool { return false }).Match(dev))
+}
+
+func TestQueryRun(t *testing.T) {
+	cli, _, _ := newTestClient(t,
+		&pb.Device{Devid: "dev-1", GroupId: "group-1", InstallStatus: pb.InstallStatus_Installed, Battery: 10},
+		&pb.Device{Devid: "dev-2", GroupId: "group-1", InstallStatus: pb.InstallStatus_Installed, Battery: 90},
+		&pb.Device{Devid: "dev-3", GroupId: "group-1", InstallStatus: pb.InstallStatus_Initial, Battery: 10},
+		&pb.Device{Devid: "dev-4", GroupId: "group-2", InstallStatus: pb.InstallStatus_Installed, Battery: 10},
+	)
+	ctx := context.Background()
+
+	devs, err := Query().InGroup("group-1").WithStatus(pb.InstallStatus_Installed).BatteryBelow(50).Run(ctx, cli)
+	assert.Nil(t, err)
+	assert.Len(t, devs, 1)
+	assert.Equal(t, "dev-1", devs[0].Devid)
+
+	devs, err = Query().BatteryBelow(50).Run(ctx, cli)
+	assert.Nil(t, err)
+
+	devids := []string{}
+	for _, dev := range devs {
+		devids = append(devids, dev.Devid)
+	}
+	sort.Strings(devids)
+	assert.Equal(t, []string{"dev-1", "dev-3", "dev-4"}, devids)
+}
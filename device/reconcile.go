@@ -0,0 +1,170 @@
+package device
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/api/iterator"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+// ConfigDrift is difference between desired config and config which device reported.
+type ConfigDrift struct {
+	Devid      string
+	ReportedAt time.Time
+	Diffs      []ConfigDiff
+}
+
+// ReconcileReport is result of Reconcile.
+type ReconcileReport struct {
+	// Drifted is devices which have different config, sorted by devid.
+	Drifted []ConfigDrift
+	InSync  int
+	// Unreported is devids which did not report config yet.
+	Unreported []string
+}
+
+type deviceReport struct {
+	appConfig *parser.ApplicationConfig
+	alive     *parser.AlivePayload
+	time      time.Time
+}
+
+// Reconciler compares desired config of devices with config which devices reported.
+// Reports are fed by Observe, usually with payload of IngestResult.
+type Reconciler struct {
+	Client Client
+	// Desired returns desired config of device.
+	// Values which DeviceService keeps are desired in application mode which device reported if nil.
+	Desired func(dev *pb.Device) Config
+	// Fields are names of Config fields to compare.
+	// Fields which ApplyConfig applies in desired application mode are compared if empty.
+	Fields []string
+
+	mu      sync.Mutex
+	reports map[string]*deviceReport
+}
+
+// NewReconciler creates reconciler which compares values which DeviceService keeps.
+func NewReconciler(cli Client) *Reconciler {
+	return &Reconciler{Client: cli}
+}
+
+// Observe keeps config in payload as reported by device.
+// Payloads other than parser.ApplicationConfig and *parser.AlivePayload are ignored.
+func (r *Reconciler) Observe(devid string, payload interface{}, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.reports == nil {
+		r.reports = make(map[string]*deviceReport)
+	}
+
+	report, ok := r.reports[devid]
+	if !ok {
+		report = &deviceReport{}
+	}
+
+	switch p := payload.(type) {
+	case parser.ApplicationConfig:
+		report.appConfig = &p
+	case *parser.ApplicationConfig:
+		report.appConfig = p
+	case *parser.AlivePayload:
+		report.alive = p
+	default:
+		return
+	}
+
+	report.time = at
+	r.reports[devid] = report
+}
+
+func (r *Reconciler) report(devid string) (deviceReport, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report, ok := r.reports[devid]
+	if !ok {
+		return deviceReport{}, false
+	}
+
+	return *report, true
+}
+
+// Drift returns difference of device, or nil if device did not report config.
+func (r *Reconciler) Drift(dev *pb.Device) *ConfigDrift {
+	report, ok := r.report(dev.Devid)
+	if !ok {
+		return nil
+	}
+
+	var desired Config
+	switch {
+	case r.Desired != nil:
+		desired = r.Desired(dev)
+	case report.appConfig != nil:
+		// DeviceService does not keep application mode.
+		desired = Config{AppMode: report.appConfig.AppMode}.Stored(dev)
+	default:
+		desired = Config{}.Stored(dev)
+	}
+
+	fields := r.Fields
+	if len(fields) == 0 {
+		fields = desired.appliedFields()
+	}
+
+	return &ConfigDrift{
+		Devid:      dev.Devid,
+		ReportedAt: report.time,
+		Diffs:      filterDiffs(desired.Diff(desired.Reported(report.appConfig, report.alive)), fields),
+	}
+}
+
+// Reconcile compares config of devices which filter matches.
+// Devices which are compared before error are kept in report.
+func (r *Reconciler) Reconcile(ctx context.Context, filter Filter) (*ReconcileReport, error) {
+	it := r.Client.FilterListIter(ctx, filter.Request())
+	defer it.Close()
+
+	result := &ReconcileReport{
+		Drifted:    make([]ConfigDrift, 0),
+		Unreported: make([]string, 0),
+	}
+
+	var err error
+	for {
+		var dev *pb.Device
+		dev, err = it.Next()
+		if err == iterator.Done {
+			err = nil
+			break
+		}
+
+		if err != nil {
+			break
+		}
+
+		drift := r.Drift(dev)
+		switch {
+		case drift == nil:
+			result.Unreported = append(result.Unreported, dev.Devid)
+		case len(drift.Diffs) == 0:
+			result.InSync++
+		default:
+			result.Drifted = append(result.Drifted, *drift)
+		}
+	}
+
+	sort.SliceStable(result.Drifted, func(i, j int) bool {
+		return result.Drifted[i].Devid < result.Drifted[j].Devid
+	})
+	sort.Strings(result.Unreported)
+
+	return result, err
+}
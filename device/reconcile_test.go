@@ -0,0 +1,113 @@
+package device
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+func TestReconciler(t *testing.T) {
+	stored := func(devid string, blocks uint32) *pb.Device {
+		return &pb.Device{
+			Devid:               devid,
+			SensorRange:         pb.SensorRangeType_Gravity16,
+			IntThresholdMg:      1900,
+			DecisionThresholdMg: 320,
+			WaveBlocks:          blocks,
+			Period:              360,
+		}
+	}
+
+	cli, _, _ := newTestClient(t, stored("in-sync", 12), stored("drifted", 24), stored("silent", 12))
+	ctx := context.Background()
+	now := time.Now()
+
+	frame, _ := parser.NewFrameParser(testAliveFrame)
+	_, _ = frame.Header()
+	alive, _ := frame.Alive()
+
+	appConfig := parser.ApplicationConfig{
+		AppMode:              parser.AppModeImpact,
+		BMARange:             parser.ConfigBMARange16G,
+		BMAHighGThresholdMg:  1900,
+		NRFImpactThresholdMg: 320,
+	}
+
+	r := NewReconciler(cli)
+	r.Observe("in-sync", alive, now)
+	r.Observe("in-sync", appConfig, now)
+	r.Observe("drifted", alive, now)
+	r.Observe("drifted", &appConfig, now)
+	r.Observe("silent", parser.PowerUp{}, now)
+
+	report, err := r.Reconcile(ctx, Filter{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, report.InSync)
+	assert.Equal(t, []string{"silent"}, report.Unreported)
+	assert.Len(t, report.Drifted, 1)
+	assert.Equal(t, "drifted", report.Drifted[0].Devid)
+	assert.Equal(t, now, report.Drifted[0].ReportedAt)
+	assert.Equal(t, []ConfigDiff{{Field: "LogBlocks", Desired: 24, Reported: 12}}, report.Drifted[0].Diffs)
+
+	// Desired config and compared fields can be replaced.
+	r.Desired = func(dev *pb.Device) Config {
+		cfg := Config{AppMode: parser.AppModeMRMT}.Stored(dev)
+		cfg.LogBlocks = 12
+		return cfg
+	}
+	r.Fields = []string{"AppMode", "LogBlocks"}
+
+	report, err = r.Reconcile(ctx, Filter{})
+	assert.Nil(t, err)
+	assert.Len(t, report.Drifted, 2)
+	assert.Equal(t, []ConfigDiff{{Field: "AppMode", Desired: parser.AppModeMRMT, Reported: parser.AppModeImpact}}, report.Drifted[1].Diffs)
+}
+
+func TestReconcilerAppMode(t *testing.T) {
+	stored := func(devid string) *pb.Device {
+		return &pb.Device{
+			Devid:               devid,
+			SensorRange:         pb.SensorRangeType_Gravity16,
+			IntThresholdMg:      1900,
+			DecisionThresholdMg: 320,
+			WaveBlocks:          12,
+			Period:              360,
+		}
+	}
+
+	cli, _, _ := newTestClient(t, stored("mrmt"), stored("impact"))
+	ctx := context.Background()
+	now := time.Now()
+
+	frame, _ := parser.NewFrameParser(testAliveFrame)
+	_, _ = frame.Header()
+	alive, _ := frame.Alive()
+
+	appConfig := func(mode parser.ApplicationMode) parser.ApplicationConfig {
+		return parser.ApplicationConfig{
+			AppMode:              mode,
+			BMARange:             parser.ConfigBMARange16G,
+			BMAHighGThresholdMg:  1900,
+			NRFImpactThresholdMg: 500,
+		}
+	}
+
+	r := NewReconciler(cli)
+	r.Observe("mrmt", alive, now)
+	r.Observe("mrmt", appConfig(parser.AppModeMRMT), now)
+	r.Observe("impact", alive, now)
+	r.Observe("impact", appConfig(parser.AppModeImpact), now)
+
+	// Stale decision threshold is not applied to device out of impact mode.
+	report, err := r.Reconcile(ctx, Filter{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, report.InSync)
+	assert.Len(t, report.Drifted, 1)
+	assert.Equal(t, "impact", report.Drifted[0].Devid)
+	assert.Equal(t, []ConfigDiff{{Field: "ImpactThresholdMg", Desired: 320, Reported: 500}}, report.Drifted[0].Diffs)
+}
@@ -0,0 +1,367 @@
+package device
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/api/iterator"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+const (
+	// minAliasScore is score below which alias does not match.
+	minAliasScore = 0.6
+	// partialAliasWeight scales score of query which matches part of alias with typo.
+	partialAliasWeight = 0.8
+
+	earthRadiusMeters = 6371000.0
+)
+
+// SearchResult is device which matches search.
+type SearchResult struct {
+	Device *pb.Device
+	// Score is similarity of alias from 0 to 1.
+	Score float64
+	// Distance is distance from center in meters.
+	Distance float64
+}
+
+// IndexUpdate is changes which Refresh applied to index.
+type IndexUpdate struct {
+	Added   []string
+	Updated []string
+	Removed []string
+}
+
+type indexEntry struct {
+	dev   *pb.Device
+	alias []rune
+}
+
+// SearchIndex is client-side index of devices for search by alias, devid and location.
+// Index is built from FilterList and refreshed incrementally, and it can also be fed by Put and Remove.
+type SearchIndex struct {
+	filter Filter
+
+	mu          sync.RWMutex
+	entries     map[string]*indexEntry
+	devids      []string
+	refreshedAt time.Time
+}
+
+// NewSearchIndex creates empty index of devices which filter matches.
+func NewSearchIndex(filter Filter) *SearchIndex {
+	return &SearchIndex{
+		filter:  filter,
+		entries: make(map[string]*indexEntry),
+		devids:  make([]string, 0),
+	}
+}
+
+// Refresh lists devices and applies changes since previous refresh.
+// Index is not changed if listing fails.
+func (idx *SearchIndex) Refresh(ctx context.Context, cli Client) (*IndexUpdate, error) {
+	it := cli.FilterListIter(ctx, idx.filter.Request())
+	defer it.Close()
+
+	devs := make(map[string]*pb.Device)
+	for {
+		dev, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		devs[dev.Devid] = dev
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	update := &IndexUpdate{
+		Added:   make([]string, 0),
+		Updated: make([]string, 0),
+		Removed: make([]string, 0),
+	}
+
+	for devid, dev := range devs {
+		entry, ok := idx.entries[devid]
+		switch {
+		case !ok:
+			update.Added = append(update.Added, devid)
+		case !proto.Equal(entry.dev, dev):
+			update.Updated = append(update.Updated, devid)
+		default:
+			continue
+		}
+
+		idx.put(dev)
+	}
+
+	for devid := range idx.entries {
+		if _, ok := devs[devid]; !ok {
+			update.Removed = append(update.Removed, devid)
+			delete(idx.entries, devid)
+		}
+	}
+
+	if len(update.Added) > 0 || len(update.Removed) > 0 {
+		idx.sortDevids()
+	}
+
+	idx.refreshedAt = time.Now()
+
+	sort.Strings(update.Added)
+	sort.Strings(update.Updated)
+	sort.Strings(update.Removed)
+
+	return update, nil
+}
+
+// RefreshedAt returns time of last successful refresh.
+func (idx *SearchIndex) RefreshedAt() time.Time {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return idx.refreshedAt
+}
+
+// Len returns number of indexed devices.
+func (idx *SearchIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.entries)
+}
+
+// Put adds or replaces device, e.g. on WatchEvent.
+func (idx *SearchIndex) Put(dev *pb.Device) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	_, ok := idx.entries[dev.Devid]
+	idx.put(dev)
+
+	if !ok {
+		idx.sortDevids()
+	}
+}
+
+// Remove removes device of devid.
+func (idx *SearchIndex) Remove(devid string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if _, ok := idx.entries[devid]; ok {
+		delete(idx.entries, devid)
+		idx.sortDevids()
+	}
+}
+
+// put indexes device. Caller should hold mu and sort devids if device is new.
+func (idx *SearchIndex) put(dev *pb.Device) {
+	idx.entries[dev.Devid] = &indexEntry{dev: dev, alias: normalizeAlias(dev.Alias)}
+}
+
+// sortDevids rebuilds sorted devids. Caller should hold mu.
+func (idx *SearchIndex) sortDevids() {
+	idx.devids = idx.devids[:0]
+	for devid := range idx.entries {
+		idx.devids = append(idx.devids, devid)
+	}
+	sort.Strings(idx.devids)
+}
+
+// Get returns indexed device of devid.
+func (idx *SearchIndex) Get(devid string) (*pb.Device, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	entry, ok := idx.entries[devid]
+	if !ok {
+		return nil, false
+	}
+
+	return entry.dev, true
+}
+
+// DevidPrefix returns devices whose devid starts with prefix, sorted by devid.
+func (idx *SearchIndex) DevidPrefix(prefix string) []*pb.Device {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	devs := make([]*pb.Device, 0)
+	for i := sort.SearchStrings(idx.devids, prefix); i < len(idx.devids); i++ {
+		if !strings.HasPrefix(idx.devids[i], prefix) {
+			break
+		}
+		devs = append(devs, idx.entries[idx.devids[i]].dev)
+	}
+
+	return devs
+}
+
+// Alias returns devices whose alias is similar to query, best first.
+// Case, spaces and punctuation are ignored and a few typos are tolerated.
+// Zero or negative limit returns every match.
+func (idx *SearchIndex) Alias(query string, limit int) []SearchResult {
+	results := make([]SearchResult, 0)
+
+	q := normalizeAlias(query)
+	if len(q) == 0 {
+		return results
+	}
+
+	idx.mu.RLock()
+	for _, entry := range idx.entries {
+		if score := aliasScore(q, entry.alias); score >= minAliasScore {
+			results = append(results, SearchResult{Device: entry.dev, Score: score})
+		}
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Device.Devid < results[j].Device.Devid
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// Near returns devices within radius in meters from latitude and longitude, nearest first.
+// Devices without location are not matched.
+func (idx *SearchIndex) Near(latitude, longitude, radius float64) []SearchResult {
+	results := make([]SearchResult, 0)
+
+	idx.mu.RLock()
+	for _, entry := range idx.entries {
+		if entry.dev.Latitude == 0 && entry.dev.Longitude == 0 {
+			continue
+		}
+
+		if dist := distance(latitude, longitude, entry.dev.Latitude, entry.dev.Longitude); dist <= radius {
+			results = append(results, SearchResult{Device: entry.dev, Distance: dist})
+		}
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Distance != results[j].Distance {
+			return results[i].Distance < results[j].Distance
+		}
+		return results[i].Device.Devid < results[j].Device.Devid
+	})
+
+	return results
+}
+
+// normalizeAlias returns lower case letters and digits of alias.
+func normalizeAlias(alias string) []rune {
+	runes := make([]rune, 0, len(alias))
+	for _, r := range alias {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			runes = append(runes, unicode.ToLower(r))
+		}
+	}
+	return runes
+}
+
+// aliasScore returns similarity of normalized query and alias.
+func aliasScore(query, alias []rune) float64 {
+	q, a := string(query), string(alias)
+	switch {
+	case len(alias) == 0:
+		return 0
+	case q == a:
+		return 1
+	case strings.HasPrefix(a, q):
+		return 0.9
+	case strings.Contains(a, q):
+		return 0.8
+	}
+
+	score := similarity(query, alias)
+
+	// Query which has typo in part of alias.
+	for i := 0; i+len(query) <= len(alias); i++ {
+		if partial := partialAliasWeight * similarity(query, alias[i:i+len(query)]); partial > score {
+			score = partial
+		}
+	}
+
+	return score
+}
+
+// similarity returns 1 - edit distance / longer length.
+func similarity(a, b []rune) float64 {
+	longer := len(a)
+	if len(b) > longer {
+		longer = len(b)
+	}
+
+	if longer == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(a, b))/float64(longer)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// distance returns great-circle distance in meters by haversine formula.
+func distance(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat, dLon := rad(lat2-lat1), rad(lon2-lon1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(h))
+}
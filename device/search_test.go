@@ -0,0 +1,120 @@
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+func searchDevids(results []SearchResult) []string {
+	devids := make([]string, len(results))
+	for i, result := range results {
+		devids[i] = result.Device.Devid
+	}
+	return devids
+}
+
+func TestSearchIndex(t *testing.T) {
+	cli, _, _ := newTestClient(t,
+		&pb.Device{Devid: "0000a1", Alias: "Bridge-3 North", GroupId: "site", Latitude: 37.5665, Longitude: 126.9780},
+		&pb.Device{Devid: "0000a2", Alias: "Bridge-3 South", GroupId: "site", Latitude: 37.5700, Longitude: 126.9780},
+		&pb.Device{Devid: "0000b1", Alias: "Pump Room", GroupId: "site", Latitude: 35.1796, Longitude: 129.0756},
+		&pb.Device{Devid: "0000c1", Alias: "Warehouse", GroupId: "site"},
+		&pb.Device{Devid: "0000d1", Alias: "Bridge-3 North", GroupId: "other"},
+	)
+	ctx := context.Background()
+
+	idx := NewSearchIndex(Filter{GroupID: FilterGroupID{Value: "site"}})
+	assert.True(t, idx.RefreshedAt().IsZero())
+
+	update, err := idx.Refresh(ctx, cli)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"0000a1", "0000a2", "0000b1", "0000c1"}, update.Added)
+	assert.Empty(t, update.Updated)
+	assert.Empty(t, update.Removed)
+	assert.Equal(t, 4, idx.Len())
+	assert.False(t, idx.RefreshedAt().IsZero())
+
+	// Alias
+	results := idx.Alias("bridge 3 north", 0)
+	assert.Equal(t, []string{"0000a1", "0000a2"}, searchDevids(results))
+	assert.Equal(t, 1.0, results[0].Score)
+	assert.True(t, results[1].Score < results[0].Score)
+
+	assert.Equal(t, []string{"0000a1", "0000a2"}, searchDevids(idx.Alias("bridge", 0)))
+	assert.Equal(t, []string{"0000a1"}, searchDevids(idx.Alias("bridge", 1)))
+	assert.Equal(t, []string{"0000b1"}, searchDevids(idx.Alias("pmup room", 0)))
+	assert.Equal(t, []string{"0000c1"}, searchDevids(idx.Alias("warehose", 0)))
+	assert.Empty(t, idx.Alias("office", 0))
+	assert.Empty(t, idx.Alias(" - ", 0))
+
+	// Devid prefix
+	devs := idx.DevidPrefix("0000a")
+	assert.Len(t, devs, 2)
+	assert.Equal(t, "0000a1", devs[0].Devid)
+	assert.Equal(t, "0000a2", devs[1].Devid)
+	assert.Len(t, idx.DevidPrefix(""), 4)
+	assert.Empty(t, idx.DevidPrefix("0000d"))
+
+	// Location
+	results = idx.Near(37.5665, 126.9780, 1000)
+	assert.Equal(t, []string{"0000a1", "0000a2"}, searchDevids(results))
+	assert.Equal(t, 0.0, results[0].Distance)
+	assert.InDelta(t, 389, results[1].Distance, 1)
+	assert.Equal(t, []string{"0000a1", "0000a2", "0000b1"}, searchDevids(idx.Near(37.5665, 126.9780, 400000)))
+
+	// Incremental refresh
+	_, err = cli.UpdateInfo(ctx, &pb.DeviceInfoUpdateRequest{Devid: "0000c1", Alias: &pb.DeviceInfoUpdateRequest_AliasValue{AliasValue: "Cold Storage"}})
+	assert.Nil(t, err)
+	_, err = cli.UpdateInfo(ctx, &pb.DeviceInfoUpdateRequest{Devid: "0000b1", GroupId: &pb.DeviceInfoUpdateRequest_GroupIdValue{GroupIdValue: "other"}})
+	assert.Nil(t, err)
+	_, err = cli.UpdateInfo(ctx, &pb.DeviceInfoUpdateRequest{Devid: "0000d1", GroupId: &pb.DeviceInfoUpdateRequest_GroupIdValue{GroupIdValue: "site"}})
+	assert.Nil(t, err)
+
+	update, err = idx.Refresh(ctx, cli)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"0000d1"}, update.Added)
+	assert.Equal(t, []string{"0000c1"}, update.Updated)
+	assert.Equal(t, []string{"0000b1"}, update.Removed)
+	assert.Equal(t, 4, idx.Len())
+	assert.Equal(t, []string{"0000c1"}, searchDevids(idx.Alias("cold storage", 0)))
+	assert.Empty(t, idx.Alias("warehouse", 0))
+	assert.Len(t, idx.DevidPrefix("0000d"), 1)
+
+	update, err = idx.Refresh(ctx, cli)
+	assert.Nil(t, err)
+	assert.Empty(t, update.Added)
+	assert.Empty(t, update.Updated)
+	assert.Empty(t, update.Removed)
+
+	// Put and Remove
+	idx.Put(&pb.Device{Devid: "0000e1", Alias: "Gate"})
+	dev, ok := idx.Get("0000e1")
+	assert.True(t, ok)
+	assert.Equal(t, "Gate", dev.Alias)
+	assert.Len(t, idx.DevidPrefix("0000e"), 1)
+
+	idx.Remove("0000e1")
+	_, ok = idx.Get("0000e1")
+	assert.False(t, ok)
+	assert.Empty(t, idx.DevidPrefix("0000e"))
+	assert.Equal(t, 4, idx.Len())
+}
+
+func TestSearchIndexRefreshError(t *testing.T) {
+	cli, _, _ := newTestClient(t, &pb.Device{Devid: "dev-1", Alias: "Gate"})
+
+	idx := NewSearchIndex(Filter{})
+	_, err := idx.Refresh(context.Background(), cli)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = idx.Refresh(ctx, cli)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, idx.Len())
+}
@@ -0,0 +1,277 @@
+package device
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// seqModulo is range of 8 bits sequence counter in frame header.
+	seqModulo = 256
+
+	// recentSeqSize is number of sequences remembered per device to find redelivered frame.
+	// It should be far less than seqModulo because sequence wraps around.
+	recentSeqSize = 16
+)
+
+// PacketStats is statistics of received frames of device within time range.
+type PacketStats struct {
+	Devid    string
+	TimeFrom time.Time
+	TimeTo   time.Time
+
+	// Received is number of frames except duplicates.
+	Received   int
+	Duplicates int
+	Missed     int
+	Late       int
+	// Loss is ratio of missed frames to expected frames.
+	Loss float64
+}
+
+type sequenceState struct {
+	last   int
+	recent []int
+	// missing is sequences which were counted as missed and may still arrive late.
+	missing map[int]bool
+	// reserved is sequences of frames which are being ingested.
+	reserved map[int]bool
+}
+
+func (s *sequenceState) contains(seq int) bool {
+	if s.reserved[seq] {
+		return true
+	}
+
+	for _, recent := range s.recent {
+		if recent == seq {
+			return true
+		}
+	}
+
+	return false
+}
+
+// advance makes seq latest and remembers missed sequences before it.
+// Sequences which are more than half of sequence range behind are forgotten.
+func (s *sequenceState) advance(seq, missed int) {
+	for i := 1; i <= missed && i <= seqModulo/2; i++ {
+		s.missing[(seq-i+seqModulo)%seqModulo] = true
+	}
+
+	s.last = seq
+	for m := range s.missing {
+		if behind := (seq - m + seqModulo) % seqModulo; behind == 0 || behind > seqModulo/2 {
+			delete(s.missing, m)
+		}
+	}
+}
+
+func (s *sequenceState) add(seq int) {
+	s.recent = append(s.recent, seq)
+	if len(s.recent) > recentSeqSize {
+		s.recent = s.recent[len(s.recent)-recentSeqSize:]
+	}
+}
+
+// SequenceTracker tracks sequence of frames per device and keeps it in LogStore.
+//
+// Frame which has one of recent sequences is duplicate.
+// Frame which is more than half of sequence range behind latest frame is late frame
+// if it was counted as missed when newer frame arrived.
+// Otherwise frames were lost for more than half of sequence range, and tracking resyncs to the frame.
+type SequenceTracker struct {
+	store LogStore
+
+	mu     sync.Mutex
+	states map[string]*sequenceState
+}
+
+// NewSequenceTracker creates tracker which keeps sequence logs in store.
+func NewSequenceTracker(store LogStore) *SequenceTracker {
+	return &SequenceTracker{
+		store:  store,
+		states: make(map[string]*sequenceState),
+	}
+}
+
+// state returns tracking state of device, restoring it from store at first.
+// Caller should hold mu.
+func (t *SequenceTracker) state(ctx context.Context, devid string) (*sequenceState, error) {
+	if state, ok := t.states[devid]; ok {
+		return state, nil
+	}
+
+	logs, err := t.store.SequenceLogs(ctx, devid, time.Unix(0, 0), time.Now(), 0, recentSeqSize)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &sequenceState{last: -1, missing: make(map[int]bool), reserved: make(map[int]bool)}
+	for i := len(logs) - 1; i >= 0; i-- {
+		if logs[i].Duplicate {
+			continue
+		}
+
+		state.add(logs[i].Seq)
+		if logs[i].Late {
+			delete(state.missing, logs[i].Seq)
+		} else {
+			state.advance(logs[i].Seq, logs[i].Missed)
+		}
+	}
+
+	t.states[devid] = state
+
+	return state, nil
+}
+
+// Seen returns true if frame of seq is already tracked or reserved.
+func (t *SequenceTracker) Seen(ctx context.Context, devid string, seq int) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, err := t.state(ctx, devid)
+	if err != nil {
+		return false, err
+	}
+
+	return state.contains(seq), nil
+}
+
+// Reserve marks frame of seq as being processed and returns true,
+// or returns false if frame is already tracked or reserved.
+// Reserved frame should be recorded by Commit or given up by Release.
+func (t *SequenceTracker) Reserve(ctx context.Context, devid string, seq int) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, err := t.state(ctx, devid)
+	if err != nil {
+		return false, err
+	}
+
+	if state.contains(seq) {
+		return false, nil
+	}
+
+	state.reserved[seq] = true
+
+	return true, nil
+}
+
+// Release gives up reserved frame of seq, e.g. if it could not be stored, so that redelivered frame is processed again.
+func (t *SequenceTracker) Release(devid string, seq int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if state, ok := t.states[devid]; ok {
+		delete(state.reserved, seq)
+	}
+}
+
+// Commit records reserved frame of seq which is received at given time.
+// Reservation is released even if recording fails.
+func (t *SequenceTracker) Commit(ctx context.Context, devid string, seq int, at time.Time) (*SequenceLog, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, err := t.state(ctx, devid)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(state.reserved, seq)
+
+	return t.track(ctx, state, devid, seq, at)
+}
+
+// Track records frame of seq which is received at given time.
+func (t *SequenceTracker) Track(ctx context.Context, devid string, seq int, at time.Time) (*SequenceLog, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, err := t.state(ctx, devid)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.track(ctx, state, devid, seq, at)
+}
+
+// track records frame in state. Caller should hold mu.
+func (t *SequenceTracker) track(ctx context.Context, state *sequenceState, devid string, seq int, at time.Time) (*SequenceLog, error) {
+	newLog := SequenceLog{Devid: devid, Time: at, Seq: seq}
+
+	switch delta := ((seq-state.last)%seqModulo + seqModulo) % seqModulo; {
+	case state.contains(seq):
+		newLog.Duplicate = true
+	case state.last < 0:
+		state.advance(seq, 0)
+	case delta > seqModulo/2 && state.missing[seq]:
+		newLog.Late = true
+		delete(state.missing, seq)
+	default:
+		// Frame far behind which was not missed follows long loss.
+		newLog.Missed = delta - 1
+		state.advance(seq, newLog.Missed)
+	}
+
+	if err := t.store.PutSequenceLog(ctx, &newLog); err != nil {
+		return nil, err
+	}
+
+	if !newLog.Duplicate {
+		state.add(seq)
+	}
+
+	return &newLog, nil
+}
+
+// Stats returns statistics of frames of device within time range.
+func (t *SequenceTracker) Stats(ctx context.Context, devid string, timeFrom, timeTo time.Time) (*PacketStats, error) {
+	if timeFrom.After(timeTo) {
+		return nil, ErrInvalidParameter
+	}
+
+	stats := &PacketStats{Devid: devid, TimeFrom: timeFrom, TimeTo: timeTo}
+
+	for offset := 0; ; {
+		page, err := t.store.SequenceLogs(ctx, devid, timeFrom, timeTo, offset, statusLogPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, l := range page {
+			switch {
+			case l.Duplicate:
+				stats.Duplicates++
+			case l.Late:
+				stats.Received++
+				stats.Late++
+			default:
+				stats.Received++
+				stats.Missed += l.Missed
+			}
+		}
+
+		if len(page) < statusLogPageSize {
+			break
+		}
+
+		offset += len(page)
+	}
+
+	// Late frame was counted as missed.
+	stats.Missed -= stats.Late
+	if stats.Missed < 0 {
+		stats.Missed = 0
+	}
+
+	if expected := stats.Received + stats.Missed; expected > 0 {
+		stats.Loss = float64(stats.Missed) / float64(expected)
+	}
+
+	return stats, nil
+}
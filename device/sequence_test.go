@@ -0,0 +1,144 @@
+package device
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+func TestSequenceTracker(t *testing.T) {
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour)
+
+	for name, store := range newTestLogStores(t) {
+		tracker := NewSequenceTracker(store)
+
+		// 253, 254, (255 missed), 0, 0 again, 254 again, (1, 2 missed), 3, 255 late.
+		seqs := []int{253, 254, 0, 0, 254, 3, 255}
+		logs := make([]*SequenceLog, len(seqs))
+		for i, seq := range seqs {
+			l, err := tracker.Track(ctx, "dev-1", seq, base.Add(time.Duration(i)*time.Minute))
+			assert.Nil(t, err, name)
+			logs[i] = l
+		}
+
+		assert.Equal(t, 0, logs[1].Missed, name)
+		assert.Equal(t, 1, logs[2].Missed, name)
+		assert.True(t, logs[3].Duplicate, name)
+		assert.True(t, logs[4].Duplicate, name)
+		assert.Equal(t, 2, logs[5].Missed, name)
+		assert.True(t, logs[6].Late, name)
+
+		stats, err := tracker.Stats(ctx, "dev-1", base, time.Now())
+		assert.Nil(t, err, name)
+		assert.Equal(t, 5, stats.Received, name)
+		assert.Equal(t, 2, stats.Duplicates, name)
+		assert.Equal(t, 1, stats.Late, name)
+		assert.Equal(t, 2, stats.Missed, name)
+		assert.InDelta(t, 2.0/7, stats.Loss, 1e-9, name)
+
+		// State is restored from store.
+		restored := NewSequenceTracker(store)
+		seen, err := restored.Seen(ctx, "dev-1", 3)
+		assert.Nil(t, err, name)
+		assert.True(t, seen, name)
+
+		l, err := restored.Track(ctx, "dev-1", 5, time.Now())
+		assert.Nil(t, err, name)
+		assert.Equal(t, 1, l.Missed, name)
+
+		_, err = tracker.Stats(ctx, "dev-1", time.Now(), base)
+		assert.Equal(t, ErrInvalidParameter, err, name)
+	}
+}
+
+func TestSequenceTrackerLongLoss(t *testing.T) {
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour)
+
+	for name, store := range newTestLogStores(t) {
+		tracker := NewSequenceTracker(store)
+
+		// 10, (11 to 210 lost), 211, 212, (213 missed), 214.
+		seqs := []int{10, 211, 212, 214}
+		logs := make([]*SequenceLog, len(seqs))
+		for i, seq := range seqs {
+			l, err := tracker.Track(ctx, "dev-1", seq, base.Add(time.Duration(i)*time.Minute))
+			assert.Nil(t, err, name)
+			logs[i] = l
+		}
+
+		assert.Equal(t, 200, logs[1].Missed, name)
+		assert.False(t, logs[1].Late, name)
+		assert.Equal(t, 0, logs[2].Missed, name)
+		assert.False(t, logs[2].Late, name)
+		assert.Equal(t, 1, logs[3].Missed, name)
+
+		// Missed sequences are restored from store.
+		restored := NewSequenceTracker(store)
+		l, err := restored.Track(ctx, "dev-1", 213, base.Add(10*time.Minute))
+		assert.Nil(t, err, name)
+		assert.True(t, l.Late, name)
+
+		stats, err := restored.Stats(ctx, "dev-1", base, time.Now())
+		assert.Nil(t, err, name)
+		assert.Equal(t, 5, stats.Received, name)
+		assert.Equal(t, 1, stats.Late, name)
+		assert.Equal(t, 200, stats.Missed, name)
+	}
+}
+
+func TestSequenceTrackerReserve(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewSequenceTracker(NewMemoryLogStore())
+
+	reserved, err := tracker.Reserve(ctx, "dev-1", 10)
+	assert.Nil(t, err)
+	assert.True(t, reserved)
+
+	// Frame which is being processed is duplicate.
+	reserved, _ = tracker.Reserve(ctx, "dev-1", 10)
+	assert.False(t, reserved)
+	seen, _ := tracker.Seen(ctx, "dev-1", 10)
+	assert.True(t, seen)
+
+	l, err := tracker.Track(ctx, "dev-1", 10, time.Now())
+	assert.Nil(t, err)
+	assert.True(t, l.Duplicate)
+
+	// Released frame is processed again.
+	tracker.Release("dev-1", 10)
+	reserved, _ = tracker.Reserve(ctx, "dev-1", 10)
+	assert.True(t, reserved)
+
+	l, err = tracker.Commit(ctx, "dev-1", 10, time.Now())
+	assert.Nil(t, err)
+	assert.False(t, l.Duplicate)
+
+	reserved, _ = tracker.Reserve(ctx, "dev-1", 10)
+	assert.False(t, reserved)
+
+	reserved, _ = tracker.Reserve(ctx, "dev-2", 10)
+	assert.True(t, reserved)
+}
+
+func TestPacketStats(t *testing.T) {
+	cli, _, _ := newTestClient(t,
+		&pb.Device{Devid: "dev-1", InstallStatus: pb.InstallStatus_Installed, InstallSessionKey: "s1", DevType: pb.DeviceType_InoVibe},
+	)
+	ctx := context.Background()
+
+	for _, frame := range []string{testAliveFrame, testAliveFrame, testConfigFrame} {
+		_, err := cli.Ingest(ctx, "dev-1", frame)
+		assert.Nil(t, err)
+	}
+
+	stats, err := cli.PacketStats(ctx, "dev-1", time.Now().Add(-time.Minute), time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, stats.Received)
+	assert.Equal(t, 1, stats.Duplicates)
+}
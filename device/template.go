@@ -0,0 +1,269 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/api/iterator"
+
+	"github.com/rootwarp/ino-vibe-go-sdk/group"
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+// ErrNonExistTemplate describes requested template is not in template set.
+var ErrNonExistTemplate = errors.New("Template does not exist")
+
+// ConfigTemplate is named config which is applied to devices of a site.
+type ConfigTemplate struct {
+	Name   string
+	Config Config
+}
+
+// Built-in templates.
+var (
+	// BridgeInclinationTemplate checks inclination of structure every hour.
+	BridgeInclinationTemplate = ConfigTemplate{
+		Name: "bridge-inclination",
+		Config: Config{
+			AppMode:                parser.AppModeExaInc,
+			Range:                  parser.ConfigBMARange2G,
+			HighGThresholdMg:       1500,
+			InclinationCheckPeriod: 60,
+			AlivePeriod:            6 * time.Hour,
+		},
+	}
+
+	// MachineRuntimeTemplate measures runtime of machine by vibration.
+	MachineRuntimeTemplate = ConfigTemplate{
+		Name: "machine-runtime",
+		Config: Config{
+			AppMode:                  parser.AppModeMRMT,
+			Range:                    parser.ConfigBMARange8G,
+			HighGThresholdMg:         4000,
+			MRMTOperationThresholdMg: 50,
+			MRMTShockThresholdMg:     2000,
+			AlivePeriod:              time.Hour,
+			LogEnable:                true,
+			LogInterval:              10 * time.Second,
+			LogBlocks:                12,
+		},
+	}
+)
+
+// TemplateSet is templates which are looked up by name.
+type TemplateSet struct {
+	mu        sync.RWMutex
+	templates map[string]ConfigTemplate
+}
+
+// NewTemplateSet creates set of built-in templates.
+func NewTemplateSet() *TemplateSet {
+	return &TemplateSet{
+		templates: map[string]ConfigTemplate{
+			BridgeInclinationTemplate.Name: BridgeInclinationTemplate,
+			MachineRuntimeTemplate.Name:    MachineRuntimeTemplate,
+		},
+	}
+}
+
+// Add adds or replaces template of same name.
+//
+// ErrInvalidParameter returns if name is empty.
+// ConfigError returns if config is invalid.
+func (s *TemplateSet) Add(tmpl ConfigTemplate) error {
+	if tmpl.Name == "" {
+		return ErrInvalidParameter
+	}
+
+	if err := tmpl.Config.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.templates[tmpl.Name] = tmpl
+
+	return nil
+}
+
+// Lookup returns template of name.
+func (s *TemplateSet) Lookup(name string) (ConfigTemplate, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tmpl, ok := s.templates[name]
+	return tmpl, ok
+}
+
+// List returns templates sorted by name.
+func (s *TemplateSet) List() []ConfigTemplate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tmpls := make([]ConfigTemplate, 0, len(s.templates))
+	for _, tmpl := range s.templates {
+		tmpls = append(tmpls, tmpl)
+	}
+
+	sort.Slice(tmpls, func(i, j int) bool {
+		return tmpls[i].Name < tmpls[j].Name
+	})
+
+	return tmpls
+}
+
+// GroupTree is part of group.Client which ApplyTemplate uses.
+type GroupTree interface {
+	GetName(ctx context.Context, groupID string) (string, error)
+	List(ctx context.Context, groupID string) ([]group.Group, error)
+}
+
+// TemplateOptions controls ApplyTemplate.
+type TemplateOptions struct {
+	// DryRun reports devices which would change without updating them.
+	DryRun bool
+}
+
+// TemplateChange is device of which applicable config differs from template.
+type TemplateChange struct {
+	Devid   string
+	GroupID string
+	Diffs   []ConfigDiff
+	// Err is error of update. It is always nil on dry run.
+	Err error
+}
+
+// TemplateReport is result of ApplyTemplate.
+type TemplateReport struct {
+	Template string
+	DryRun   bool
+	// Unsupported is fields of template which are neither compared nor applied, see Config.Unsupported.
+	Unsupported []string
+	// Groups are IDs of groups in subtree.
+	Groups []string
+	// Changes are sorted by devid.
+	Changes   []TemplateChange
+	Unchanged int
+}
+
+// Failed returns changes which are not applied.
+func (r *TemplateReport) Failed() []TemplateChange {
+	failed := make([]TemplateChange, 0)
+	for _, change := range r.Changes {
+		if change.Err != nil {
+			failed = append(failed, change)
+		}
+	}
+	return failed
+}
+
+// ApplyNamedTemplate applies template of name in templates.
+//
+// ErrNonExistTemplate returns if template is not in templates.
+func ApplyNamedTemplate(ctx context.Context, cli Client, groups GroupTree, templates *TemplateSet, groupID, name string, opts TemplateOptions) (*TemplateReport, error) {
+	tmpl, ok := templates.Lookup(name)
+	if !ok {
+		return nil, ErrNonExistTemplate
+	}
+
+	return ApplyTemplate(ctx, cli, groups, groupID, tmpl, opts)
+}
+
+// ApplyTemplate applies config of template to devices in group and its descendants.
+// Only values which ApplyConfig applies are compared, and devices which already have them are not updated.
+// Other values of template are reported as unsupported on dry run too.
+// Failure of update is kept in change and other devices are still updated.
+// Devices which are compared before error are kept in report.
+//
+// ConfigError returns if config of template is invalid.
+// group.ErrGroupNonExist returns if group does not exist.
+func ApplyTemplate(ctx context.Context, cli Client, groups GroupTree, groupID string, tmpl ConfigTemplate, opts TemplateOptions) (*TemplateReport, error) {
+	if err := tmpl.Config.Validate(); err != nil {
+		return nil, err
+	}
+
+	// List returns empty tree for group which does not exist, so existence is checked by itself.
+	if _, err := groups.GetName(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	tree, err := groups.List(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TemplateReport{
+		Template:    tmpl.Name,
+		DryRun:      opts.DryRun,
+		Unsupported: tmpl.Config.Unsupported(),
+		Groups:      subtreeIDs(tree),
+		Changes:     make([]TemplateChange, 0),
+	}
+
+	// Group is removed meanwhile.
+	if len(report.Groups) == 0 {
+		return nil, group.ErrGroupNonExist
+	}
+
+	for _, id := range report.Groups {
+		if err := report.applyGroup(ctx, cli, id, tmpl.Config, opts); err != nil {
+			report.sort()
+			return report, err
+		}
+	}
+
+	report.sort()
+
+	return report, nil
+}
+
+func (r *TemplateReport) applyGroup(ctx context.Context, cli Client, groupID string, cfg Config, opts TemplateOptions) error {
+	filter := Filter{GroupID: FilterGroupID{Value: groupID}}
+
+	it := cli.FilterListIter(ctx, filter.Request())
+	defer it.Close()
+
+	for {
+		dev, err := it.Next()
+		if err == iterator.Done {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		diffs := filterDiffs(cfg.Diff(cfg.Stored(dev)), cfg.appliedFields())
+		if len(diffs) == 0 {
+			r.Unchanged++
+			continue
+		}
+
+		change := TemplateChange{Devid: dev.Devid, GroupID: groupID, Diffs: diffs}
+		if !opts.DryRun {
+			change.Err = ApplyConfig(ctx, cli, dev.Devid, cfg)
+		}
+
+		r.Changes = append(r.Changes, change)
+	}
+}
+
+func (r *TemplateReport) sort() {
+	sort.SliceStable(r.Changes, func(i, j int) bool {
+		return r.Changes[i].Devid < r.Changes[j].Devid
+	})
+}
+
+// subtreeIDs returns IDs of groups in tree, parents first.
+func subtreeIDs(tree []group.Group) []string {
+	ids := make([]string, 0)
+	for _, g := range tree {
+		ids = append(ids, g.ID)
+		ids = append(ids, subtreeIDs(g.Children)...)
+	}
+	return ids
+}
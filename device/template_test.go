@@ -0,0 +1,163 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/fake/faketest"
+	"github.com/rootwarp/ino-vibe-go-sdk/group"
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+func TestTemplateSet(t *testing.T) {
+	templates := NewTemplateSet()
+
+	tmpl, ok := templates.Lookup("bridge-inclination")
+	assert.True(t, ok)
+	assert.Equal(t, parser.AppModeExaInc, tmpl.Config.AppMode)
+
+	tmpl, ok = templates.Lookup("machine-runtime")
+	assert.True(t, ok)
+	assert.Equal(t, parser.AppModeMRMT, tmpl.Config.AppMode)
+
+	for _, tmpl := range templates.List() {
+		assert.Nil(t, tmpl.Config.Validate(), tmpl.Name)
+	}
+
+	_, ok = templates.Lookup("unknown")
+	assert.False(t, ok)
+
+	assert.Equal(t, ErrInvalidParameter, templates.Add(ConfigTemplate{Config: MachineRuntimeTemplate.Config}))
+
+	var cfgErr *ConfigError
+	assert.True(t, errors.As(templates.Add(ConfigTemplate{Name: "invalid"}), &cfgErr))
+
+	custom := ConfigTemplate{Name: "custom-runtime", Config: MachineRuntimeTemplate.Config}
+	custom.Config.AlivePeriod = 3 * time.Hour
+	assert.Nil(t, templates.Add(custom))
+
+	tmpl, ok = templates.Lookup("custom-runtime")
+	assert.True(t, ok)
+	assert.Equal(t, custom, tmpl)
+
+	names := make([]string, 0)
+	for _, tmpl := range templates.List() {
+		names = append(names, tmpl.Name)
+	}
+	assert.Equal(t, []string{"bridge-inclination", "custom-runtime", "machine-runtime"}, names)
+
+	// Other sets do not have added template.
+	_, ok = NewTemplateSet().Lookup("custom-runtime")
+	assert.False(t, ok)
+}
+
+// stubGroupTree has group but lists tree or error given.
+type stubGroupTree struct {
+	tree []group.Group
+	err  error
+}
+
+func (g stubGroupTree) GetName(ctx context.Context, groupID string) (string, error) {
+	return groupID, nil
+}
+
+func (g stubGroupTree) List(ctx context.Context, groupID string) ([]group.Group, error) {
+	return g.tree, g.err
+}
+
+func TestApplyTemplate(t *testing.T) {
+	cfg := MachineRuntimeTemplate.Config
+	applied := func(devid, groupID string) *pb.Device {
+		return &pb.Device{
+			Devid:          devid,
+			GroupId:        groupID,
+			SensorRange:    pb.SensorRangeType(cfg.Range),
+			IntThresholdMg: float64(cfg.HighGThresholdMg),
+			WaveBlocks:     uint32(cfg.LogBlocks),
+			Period:         uint32(cfg.AlivePeriod / time.Minute),
+		}
+	}
+
+	outdated := applied("outdated", "line-1")
+	outdated.Period = 360
+
+	srv, conn := faketest.NewServer(t)
+	// Decision threshold is not compared in machine runtime mode.
+	impact := applied("applied", "factory")
+	impact.DecisionThresholdMg = 320
+	srv.AddDevice(impact)
+	srv.AddDevice(outdated)
+	srv.AddDevice(&pb.Device{Devid: "new", GroupId: "factory"})
+	srv.AddDevice(&pb.Device{Devid: "other", GroupId: "other"})
+	srv.AddGroup(&pb.Group{Groupid: "factory", Name: "Factory"})
+	srv.AddGroup(&pb.Group{Groupid: "line-1", Name: "Line 1", ParentId: "factory"})
+	srv.AddGroup(&pb.Group{Groupid: "other", Name: "Other"})
+
+	cli, _ := NewClient(WithConn(conn))
+	groupCli, _ := group.NewClient(group.WithConn(conn))
+	ctx := context.Background()
+
+	report, err := ApplyNamedTemplate(ctx, cli, groupCli, NewTemplateSet(), "factory", "machine-runtime", TemplateOptions{DryRun: true})
+	assert.Nil(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, "machine-runtime", report.Template)
+	assert.Equal(t, []string{"AppMode", "MRMTOperationThresholdMg", "MRMTShockThresholdMg", "LogEnable", "LogInterval"}, report.Unsupported)
+	assert.Equal(t, []string{"factory", "line-1"}, report.Groups)
+	assert.Equal(t, 1, report.Unchanged)
+	assert.Len(t, report.Changes, 2)
+	assert.Equal(t, "new", report.Changes[0].Devid)
+	assert.Equal(t, "factory", report.Changes[0].GroupID)
+	assert.Equal(t, "outdated", report.Changes[1].Devid)
+	assert.Equal(t, "line-1", report.Changes[1].GroupID)
+	assert.Equal(t, []ConfigDiff{{Field: "AlivePeriod", Desired: time.Hour, Reported: 6 * time.Hour}}, report.Changes[1].Diffs)
+	assert.Empty(t, report.Failed())
+
+	// Dry run does not update devices.
+	resp, _ := cli.Detail(ctx, "outdated")
+	assert.Equal(t, uint32(360), resp.Devices[0].Period)
+
+	report, err = ApplyTemplate(ctx, cli, groupCli, "factory", MachineRuntimeTemplate, TemplateOptions{})
+	assert.Nil(t, err)
+	assert.False(t, report.DryRun)
+	assert.Len(t, report.Changes, 2)
+	assert.Empty(t, report.Failed())
+
+	resp, _ = cli.Detail(ctx, "outdated")
+	assert.Equal(t, uint32(60), resp.Devices[0].Period)
+
+	resp, _ = cli.Detail(ctx, "other")
+	assert.Equal(t, uint32(0), resp.Devices[0].Period)
+
+	report, err = ApplyTemplate(ctx, cli, groupCli, "factory", MachineRuntimeTemplate, TemplateOptions{DryRun: true})
+	assert.Nil(t, err)
+	assert.Empty(t, report.Changes)
+	assert.Equal(t, 3, report.Unchanged)
+
+	_, err = ApplyNamedTemplate(ctx, cli, groupCli, NewTemplateSet(), "factory", "unknown", TemplateOptions{})
+	assert.Equal(t, ErrNonExistTemplate, err)
+
+	_, err = ApplyTemplate(ctx, cli, groupCli, "unknown", MachineRuntimeTemplate, TemplateOptions{})
+	assert.Equal(t, group.ErrGroupNonExist, err)
+
+	// Error of listing tree is not regarded as empty tree.
+	unavailable := errors.New("Unavailable")
+	_, err = ApplyTemplate(ctx, cli, stubGroupTree{err: unavailable}, "factory", MachineRuntimeTemplate, TemplateOptions{})
+	assert.Equal(t, unavailable, err)
+
+	_, err = ApplyTemplate(ctx, cli, stubGroupTree{tree: []group.Group{}}, "factory", MachineRuntimeTemplate, TemplateOptions{})
+	assert.Equal(t, group.ErrGroupNonExist, err)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = ApplyTemplate(canceled, cli, groupCli, "factory", MachineRuntimeTemplate, TemplateOptions{})
+	assert.NotNil(t, err)
+	assert.NotEqual(t, group.ErrGroupNonExist, err)
+
+	_, err = ApplyTemplate(ctx, cli, groupCli, "factory", ConfigTemplate{Name: "invalid"}, TemplateOptions{})
+	assert.True(t, errors.Is(err, ErrInvalidParameter))
+}
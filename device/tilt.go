@@ -0,0 +1,116 @@
+package device
+
+import (
+	"math"
+
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+// rollStability keeps roll continuous when device stands on X axis and
+// Y, Z are nearly zero. Small enough to keep error of roll below 0.3°.
+const rollStability = 0.01
+
+// Acceleration is 3-axis acceleration of device.
+// Tilt depends on direction only, so any unit can be used if it is same between readings.
+type Acceleration struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+// DefaultBaseline is orientation of uncalibrated device which lies flat.
+var DefaultBaseline = Acceleration{Z: 1000}
+
+// Tilt is orientation of device relative to baseline in degree.
+type Tilt struct {
+	// Pitch is rotation about Y axis in range of [-180, 180].
+	Pitch float64
+	// Roll is rotation about X axis in range of [-180, 180).
+	Roll float64
+	// Total is angle between gravity and baseline in range of [0, 180].
+	Total float64
+}
+
+// ConfigBaseline returns calibrated baseline which device reports in application config.
+// DefaultBaseline returns if device is not calibrated.
+func ConfigBaseline(cfg *parser.ApplicationConfig) Acceleration {
+	if cfg == nil || (cfg.BaseX == 0 && cfg.BaseY == 0 && cfg.BaseZ == 0) {
+		return DefaultBaseline
+	}
+
+	return Acceleration{X: float64(cfg.BaseX), Y: float64(cfg.BaseY), Z: float64(cfg.BaseZ)}
+}
+
+func (a Acceleration) valid() bool {
+	for _, v := range []float64{a.X, a.Y, a.Z} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+
+	return a.X != 0 || a.Y != 0 || a.Z != 0
+}
+
+func (a Acceleration) pitch() float64 {
+	return math.Atan2(-a.X, math.Hypot(a.Y, a.Z))
+}
+
+func (a Acceleration) roll() float64 {
+	sign := 1.0
+	if a.Z < 0 {
+		sign = -1
+	}
+
+	return math.Atan2(a.Y, sign*math.Sqrt(a.Z*a.Z+rollStability*a.X*a.X))
+}
+
+// ComputeTilt returns tilt of acc relative to baseline.
+// DefaultBaseline is used if baseline is zero.
+//
+// ErrInvalidInclinationValue returns if acc is zero, NaN or Inf.
+func ComputeTilt(acc, baseline Acceleration) (Tilt, error) {
+	if !acc.valid() {
+		return Tilt{}, ErrInvalidInclinationValue
+	}
+
+	if !baseline.valid() {
+		baseline = DefaultBaseline
+	}
+
+	crossX := acc.Y*baseline.Z - acc.Z*baseline.Y
+	crossY := acc.Z*baseline.X - acc.X*baseline.Z
+	crossZ := acc.X*baseline.Y - acc.Y*baseline.X
+	dot := acc.X*baseline.X + acc.Y*baseline.Y + acc.Z*baseline.Z
+
+	tilt := Tilt{
+		Pitch: degree(acc.pitch() - baseline.pitch()),
+		Roll:  degree(wrapAngle(acc.roll() - baseline.roll())),
+		Total: degree(math.Atan2(math.Sqrt(crossX*crossX+crossY*crossY+crossZ*crossZ), dot)),
+	}
+
+	return tilt, nil
+}
+
+// angle returns elevation of Z axis from XY plane in degree.
+// NaN returns if acceleration is zero.
+func angle(x, y, z float64) float64 {
+	if !(Acceleration{X: x, Y: y, Z: z}).valid() {
+		return math.NaN()
+	}
+
+	return degree(math.Atan2(z, math.Hypot(x, y)))
+}
+
+// wrapAngle wraps radian into [-π, π).
+func wrapAngle(rad float64) float64 {
+	rad = math.Mod(rad+math.Pi, 2*math.Pi)
+	if rad < 0 {
+		rad += 2 * math.Pi
+	}
+
+	return rad - math.Pi
+}
+
+func degree(rad float64) float64 {
+	return rad * (180 / math.Pi)
+}
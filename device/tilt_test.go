@@ -0,0 +1,106 @@
+package device
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rootwarp/ino-vibe-go-sdk/parser"
+)
+
+func TestComputeTilt(t *testing.T) {
+	sin30, cos30 := 500.0, 1000*math.Sqrt(3)/2
+
+	tests := []struct {
+		Desc     string
+		Acc      Acceleration
+		Baseline Acceleration
+		Expect   Tilt
+	}{
+		{Desc: "Flat", Acc: Acceleration{Z: 998}, Expect: Tilt{}},
+		{Desc: "Pitch", Acc: Acceleration{X: -sin30, Z: cos30}, Expect: Tilt{Pitch: 30, Total: 30}},
+		{Desc: "Roll", Acc: Acceleration{Y: sin30, Z: cos30}, Expect: Tilt{Roll: 30, Total: 30}},
+		{Desc: "Upside down", Acc: Acceleration{Z: -1000}, Expect: Tilt{Roll: -180, Total: 180}},
+		{Desc: "Standing on X axis", Acc: Acceleration{X: -1000}, Expect: Tilt{Pitch: 90, Total: 90}},
+		{Desc: "Calibrated baseline", Acc: Acceleration{X: 993.812, Y: -13.908, Z: 1.22}, Baseline: Acceleration{X: 993.812, Y: -13.908, Z: 1.22}, Expect: Tilt{}},
+		{Desc: "Baseline with different scale", Acc: Acceleration{Y: 1000}, Baseline: Acceleration{Y: 4096}, Expect: Tilt{}},
+	}
+
+	for _, test := range tests {
+		tilt, err := ComputeTilt(test.Acc, test.Baseline)
+		assert.Nil(t, err, test.Desc)
+		assert.InDelta(t, test.Expect.Pitch, tilt.Pitch, 1e-6, test.Desc)
+		assert.InDelta(t, test.Expect.Roll, tilt.Roll, 1e-6, test.Desc)
+		assert.InDelta(t, test.Expect.Total, tilt.Total, 1e-6, test.Desc)
+	}
+}
+
+func TestComputeTiltNearSingularity(t *testing.T) {
+	// Roll should not jump when device stands on X axis with noise on Y and Z.
+	for _, acc := range []Acceleration{{X: -1000, Y: 0.5, Z: 0.5}, {X: -1000, Y: 0.5, Z: -0.5}, {X: -1000, Y: -0.5, Z: 0.5}} {
+		tilt, err := ComputeTilt(acc, Acceleration{})
+		assert.Nil(t, err)
+		assert.InDelta(t, 90, tilt.Pitch, 0.1)
+		assert.True(t, math.Abs(tilt.Roll) < 1 || math.Abs(tilt.Roll) > 179, acc)
+		assert.InDelta(t, 90, tilt.Total, 0.1)
+	}
+}
+
+func TestComputeTiltInvalid(t *testing.T) {
+	for _, acc := range []Acceleration{{}, {X: math.NaN(), Z: 1}, {Z: math.Inf(1)}} {
+		_, err := ComputeTilt(acc, DefaultBaseline)
+		assert.Equal(t, ErrInvalidInclinationValue, err)
+	}
+
+	assert.True(t, math.IsNaN(angle(0, 0, 0)))
+	assert.Equal(t, float64(90), angle(0, 0, 998))
+}
+
+func TestConfigBaseline(t *testing.T) {
+	assert.Equal(t, DefaultBaseline, ConfigBaseline(nil))
+	assert.Equal(t, DefaultBaseline, ConfigBaseline(&parser.ApplicationConfig{}))
+	assert.Equal(t, Acceleration{X: 1, Y: -2, Z: 250}, ConfigBaseline(&parser.ApplicationConfig{BaseX: 1, BaseY: -2, BaseZ: 250}))
+}
+
+func TestAngle(t *testing.T) {
+	tests := []struct {
+		X float64
+		Y float64
+		Z float64
+	}{
+		{
+			X: 0.0,
+			Y: 0.0,
+			Z: 998.0,
+		},
+
+		{
+			X: 993.812,
+			Y: -13.908,
+			Z: -1.22,
+		},
+
+		{
+			X: 993.812,
+			Y: -13.908,
+			Z: 1.22,
+		},
+
+		{
+			X: 13.908,
+			Y: 993.812,
+			Z: 1.22,
+		},
+		{
+			X: -13.908,
+			Y: 993.812,
+			Z: 1.22,
+		},
+	}
+
+	for i, test := range tests {
+		angleZ := angle(test.X, test.Y, test.Z)
+		assert.False(t, math.IsNaN(angleZ), i)
+	}
+}
@@ -0,0 +1,214 @@
+package device
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+const defaultWatchInterval = time.Minute
+
+// WatchEventType is kind of change which Watch detected.
+type WatchEventType int
+
+// Watch event types.
+const (
+	EventError WatchEventType = iota
+	EventNewDevice
+	EventInstalled
+	EventUninstalled
+	EventBatteryDropped
+	EventConfigChanged
+	EventRemoved
+)
+
+func (t WatchEventType) String() string {
+	switch t {
+	case EventNewDevice:
+		return "NewDevice"
+	case EventInstalled:
+		return "Installed"
+	case EventUninstalled:
+		return "Uninstalled"
+	case EventBatteryDropped:
+		return "BatteryDropped"
+	case EventConfigChanged:
+		return "ConfigChanged"
+	case EventRemoved:
+		return "Removed"
+	}
+	return "Error"
+}
+
+// WatchEvent is change of device between two polls.
+// Previous is nil on EventNewDevice, and Err is set only on EventError.
+// EventRemoved is device which does not match filter any more, and Current is nil if it is deleted.
+type WatchEvent struct {
+	Type     WatchEventType
+	Devid    string
+	Previous *pb.Device
+	Current  *pb.Device
+	Err      error
+}
+
+// Watch polls devices of filter every interval and sends changes on returned channel.
+// Devices of first poll are snapshot and not reported as new.
+// Device which drops out of filter is checked by Detail and reported as EventUninstalled if it is uninstalled,
+// or EventRemoved otherwise.
+// Channel is closed when ctx is done.
+// Interval of a minute is used if interval is not positive.
+func (c *client) Watch(ctx context.Context, filter Filter, interval time.Duration) <-chan WatchEvent {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	events := make(chan WatchEvent)
+
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var snapshot map[string]*pb.Device
+
+		for {
+			devs, err := c.FilterList(ctx, filter.Request())
+			if err != nil {
+				if ctx.Err() != nil || !sendWatchEvent(ctx, events, WatchEvent{Type: EventError, Err: err}) {
+					return
+				}
+			} else {
+				current := make(map[string]*pb.Device, len(devs))
+				for _, dev := range devs {
+					current[dev.Devid] = dev
+				}
+
+				if snapshot != nil {
+					for _, event := range diffDevices(snapshot, devs) {
+						if event.Type == EventRemoved {
+							event = c.resolveRemoved(ctx, event)
+						}
+
+						if !sendWatchEvent(ctx, events, event) {
+							return
+						}
+					}
+				}
+
+				snapshot = current
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return events
+}
+
+func sendWatchEvent(ctx context.Context, events chan<- WatchEvent, event WatchEvent) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case events <- event:
+		return true
+	}
+}
+
+// resolveRemoved sets current state of removed device, and changes event to EventUninstalled if device is uninstalled.
+// Event is kept if state is not available.
+func (c *client) resolveRemoved(ctx context.Context, event WatchEvent) WatchEvent {
+	resp, err := c.Detail(ctx, event.Devid)
+	if err != nil || resp.ResultCode != pb.ResponseCode_SUCCESS || len(resp.Devices) == 0 {
+		return event
+	}
+
+	event.Current = resp.Devices[0]
+	if isUninstalled(event.Previous.InstallStatus, event.Current.InstallStatus) {
+		event.Type = EventUninstalled
+	}
+
+	return event
+}
+
+// diffDevices returns events of devices compared to previous snapshot, in order of devs.
+// Devices which are not in devs any more follow as EventRemoved, sorted by devid.
+func diffDevices(previous map[string]*pb.Device, devs []*pb.Device) []WatchEvent {
+	events := make([]WatchEvent, 0)
+
+	current := make(map[string]bool, len(devs))
+	for _, cur := range devs {
+		current[cur.Devid] = true
+	}
+
+	for _, cur := range devs {
+		prev, ok := previous[cur.Devid]
+		if !ok {
+			events = append(events, WatchEvent{Type: EventNewDevice, Devid: cur.Devid, Current: cur})
+			continue
+		}
+
+		newEvent := func(t WatchEventType) WatchEvent {
+			return WatchEvent{Type: t, Devid: cur.Devid, Previous: prev, Current: cur}
+		}
+
+		if prev.InstallStatus != pb.InstallStatus_Installed && cur.InstallStatus == pb.InstallStatus_Installed {
+			events = append(events, newEvent(EventInstalled))
+		}
+
+		if isUninstalled(prev.InstallStatus, cur.InstallStatus) {
+			events = append(events, newEvent(EventUninstalled))
+		}
+
+		if cur.Battery < prev.Battery {
+			events = append(events, newEvent(EventBatteryDropped))
+		}
+
+		if configChanged(prev, cur) {
+			events = append(events, newEvent(EventConfigChanged))
+		}
+	}
+
+	removed := make([]string, 0)
+	for devid := range previous {
+		if !current[devid] {
+			removed = append(removed, devid)
+		}
+	}
+	sort.Strings(removed)
+
+	for _, devid := range removed {
+		events = append(events, WatchEvent{Type: EventRemoved, Devid: devid, Previous: previous[devid]})
+	}
+
+	return events
+}
+
+func isUninstalled(prev, cur pb.InstallStatus) bool {
+	if prev != pb.InstallStatus_Installed && prev != pb.InstallStatus_Uninstalling {
+		return false
+	}
+	return cur == pb.InstallStatus_Initial || cur == pb.InstallStatus_Discarded
+}
+
+func configChanged(prev, cur *pb.Device) bool {
+	return prev.SensorRange != cur.SensorRange ||
+		prev.IntThresholdMg != cur.IntThresholdMg ||
+		prev.DecisionThresholdMg != cur.DecisionThresholdMg ||
+		prev.SampleRate != cur.SampleRate ||
+		prev.WaveBlocks != cur.WaveBlocks ||
+		prev.IsNotifEnabled != cur.IsNotifEnabled ||
+		prev.RecogType != cur.RecogType ||
+		prev.RecogParam_0 != cur.RecogParam_0 ||
+		prev.RecogParam_1 != cur.RecogParam_1 ||
+		prev.RecogParam_2 != cur.RecogParam_2 ||
+		!proto.Equal(prev.MuteDate, cur.MuteDate)
+}
@@ -0,0 +1,148 @@
+package device
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+func TestDiffDevices(t *testing.T) {
+	previous := map[string]*pb.Device{
+		"requested":  {Devid: "requested", InstallStatus: pb.InstallStatus_Requested},
+		"installed":  {Devid: "installed", InstallStatus: pb.InstallStatus_Installed, Battery: 80, WaveBlocks: 1},
+		"unchanged":  {Devid: "unchanged", InstallStatus: pb.InstallStatus_Installed, Battery: 80},
+		"discarding": {Devid: "discarding", InstallStatus: pb.InstallStatus_Uninstalling},
+		"removed-2":  {Devid: "removed-2", InstallStatus: pb.InstallStatus_Installed},
+		"removed-1":  {Devid: "removed-1", InstallStatus: pb.InstallStatus_Installed},
+	}
+
+	devs := []*pb.Device{
+		{Devid: "requested", InstallStatus: pb.InstallStatus_Installed},
+		{Devid: "installed", InstallStatus: pb.InstallStatus_Initial, Battery: 70, WaveBlocks: 4},
+		{Devid: "unchanged", InstallStatus: pb.InstallStatus_Installed, Battery: 85},
+		{Devid: "discarding", InstallStatus: pb.InstallStatus_Discarded},
+		{Devid: "new"},
+	}
+
+	events := diffDevices(previous, devs)
+
+	types := []WatchEventType{}
+	devids := []string{}
+	for _, event := range events {
+		types = append(types, event.Type)
+		devids = append(devids, event.Devid)
+	}
+
+	assert.Equal(t, []WatchEventType{
+		EventInstalled,
+		EventUninstalled, EventBatteryDropped, EventConfigChanged,
+		EventUninstalled,
+		EventNewDevice,
+		EventRemoved, EventRemoved,
+	}, types)
+	assert.Equal(t, []string{"requested", "installed", "installed", "installed", "discarding", "new", "removed-1", "removed-2"}, devids)
+
+	assert.Equal(t, previous["installed"], events[2].Previous)
+	assert.Equal(t, devs[1], events[2].Current)
+	assert.Nil(t, events[5].Previous)
+	assert.Equal(t, previous["removed-1"], events[6].Previous)
+	assert.Nil(t, events[6].Current)
+}
+
+func TestWatch(t *testing.T) {
+	cli, srv, _ := newTestClient(t,
+		&pb.Device{Devid: "dev-1", GroupId: "group-1", InstallStatus: pb.InstallStatus_Installed, Battery: 90},
+		&pb.Device{Devid: "dev-2", GroupId: "group-2", InstallStatus: pb.InstallStatus_Installed, Battery: 90},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := cli.Watch(ctx, Query().InGroup("group-1").Filter(), 10*time.Millisecond)
+
+	// Let first poll take snapshot.
+	time.Sleep(30 * time.Millisecond)
+
+	srv.AddDevice(&pb.Device{Devid: "dev-1", GroupId: "group-1", InstallStatus: pb.InstallStatus_Installed, Battery: 60})
+	srv.AddDevice(&pb.Device{Devid: "dev-2", GroupId: "group-2", InstallStatus: pb.InstallStatus_Installed, Battery: 10})
+
+	event := <-events
+	assert.Equal(t, EventBatteryDropped, event.Type)
+	assert.Equal(t, "dev-1", event.Devid)
+	assert.Equal(t, uint32(90), event.Previous.Battery)
+	assert.Equal(t, uint32(60), event.Current.Battery)
+
+	srv.AddDevice(&pb.Device{Devid: "dev-3", GroupId: "group-1"})
+
+	event = <-events
+	assert.Equal(t, EventNewDevice, event.Type)
+	assert.Equal(t, "dev-3", event.Devid)
+
+	srv.FailFilterList(0, status.Error(codes.Unavailable, "connection lost"))
+
+	event = <-events
+	assert.Equal(t, EventError, event.Type)
+	assert.Equal(t, codes.Unavailable, status.Code(event.Err))
+
+	cancel()
+
+	for range events {
+	}
+}
+
+func TestWatchStatusFilter(t *testing.T) {
+	cli, srv, _ := newTestClient(t,
+		&pb.Device{Devid: "dev-1", GroupId: "group-1", InstallStatus: pb.InstallStatus_Installed},
+		&pb.Device{Devid: "dev-2", GroupId: "group-1", InstallStatus: pb.InstallStatus_Installed},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := cli.Watch(ctx, Query().WithStatus(pb.InstallStatus_Installed).InGroup("group-1").Filter(), 10*time.Millisecond)
+
+	// Let first poll take snapshot.
+	time.Sleep(30 * time.Millisecond)
+
+	// Uninstalled device drops out of filter.
+	_, err := cli.Uninstall(ctx, &pb.UninstallRequest{Devid: "dev-1"})
+	assert.Nil(t, err)
+
+	event := <-events
+	assert.Equal(t, EventUninstalled, event.Type)
+	assert.Equal(t, "dev-1", event.Devid)
+	assert.Equal(t, pb.InstallStatus_Installed, event.Previous.InstallStatus)
+	assert.Equal(t, pb.InstallStatus_Initial, event.Current.InstallStatus)
+
+	// Device which moves to other group is removed.
+	srv.AddDevice(&pb.Device{Devid: "dev-2", GroupId: "group-2", InstallStatus: pb.InstallStatus_Installed})
+
+	event = <-events
+	assert.Equal(t, EventRemoved, event.Type)
+	assert.Equal(t, "dev-2", event.Devid)
+	assert.Equal(t, "group-2", event.Current.GroupId)
+
+	cancel()
+
+	for range events {
+	}
+}
+
+func TestWatchNonPositiveInterval(t *testing.T) {
+	cli, _, _ := newTestClient(t, &pb.Device{Devid: "dev-1"})
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+
+		for range cli.Watch(ctx, Filter{}, interval) {
+		}
+
+		cancel()
+	}
+}
@@ -0,0 +1,68 @@
+package fake
+
+import (
+	"context"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+const defaultAlertCount = 10
+
+type alertService struct {
+	pb.UnimplementedAlertServiceServer
+	s *Server
+}
+
+// List returns latest alerts first.
+func (a *alertService) List(ctx context.Context, req *pb.AlertListRequest) (*pb.AlertListResponse, error) {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+
+	alerts := make([]*pb.AlertListItem, 0)
+	for _, alert := range a.s.alerts {
+		switch search := req.Search.(type) {
+		case *pb.AlertListRequest_Devid:
+			if alert.Devid != search.Devid {
+				continue
+			}
+		case *pb.AlertListRequest_Groupid:
+			if alert.GetAlarmGroup().GetGroupid() != search.Groupid {
+				continue
+			}
+		case *pb.AlertListRequest_InstallSessionKey:
+			if alert.InstallSessionKey != search.InstallSessionKey {
+				continue
+			}
+		}
+
+		issued := alert.GetIssued().GetSeconds()
+		if req.DateFrom != nil && issued < req.GetDateFromValue().GetSeconds() {
+			continue
+		}
+
+		if req.DateTo != nil && issued > req.GetDateToValue().GetSeconds() {
+			continue
+		}
+
+		alerts = append(alerts, alert)
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].GetIssued().GetSeconds() > alerts[j].GetIssued().GetSeconds()
+	})
+
+	maxCount := int(req.MaxCount)
+	if maxCount == 0 {
+		maxCount = defaultAlertCount
+	}
+
+	resp := &pb.AlertListResponse{ResponseCode: pb.ResponseCode_SUCCESS}
+	for i := int(req.Offset); i < len(alerts) && len(resp.Alerts) < maxCount; i++ {
+		resp.Alerts = append(resp.Alerts, proto.Clone(alerts[i]).(*pb.AlertListItem))
+	}
+
+	return resp, nil
+}
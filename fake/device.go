@@ -0,0 +1,358 @@
+package fake
+
+import (
+	"context"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+// Install status transitions which server permits for each install request.
+var installTransitions = map[string]map[pb.InstallStatus]pb.InstallStatus{
+	"PrepareInstall": {
+		pb.InstallStatus_Initial:   pb.InstallStatus_Requested,
+		pb.InstallStatus_Discarded: pb.InstallStatus_Requested,
+	},
+	"WaitCompleteInstall": {
+		pb.InstallStatus_Requested: pb.InstallStatus_WaitInstallComplete,
+	},
+	"CompleteInstall": {
+		pb.InstallStatus_Requested:           pb.InstallStatus_Installed,
+		pb.InstallStatus_WaitInstallComplete: pb.InstallStatus_Installed,
+	},
+	"Uninstalling": {
+		pb.InstallStatus_Installed: pb.InstallStatus_Uninstalling,
+	},
+	"Uninstall": {
+		pb.InstallStatus_Installed:    pb.InstallStatus_Initial,
+		pb.InstallStatus_Uninstalling: pb.InstallStatus_Initial,
+	},
+	"Discard": {
+		pb.InstallStatus_Requested:           pb.InstallStatus_Discarded,
+		pb.InstallStatus_WaitInstallComplete: pb.InstallStatus_Discarded,
+		pb.InstallStatus_Installed:           pb.InstallStatus_Discarded,
+		pb.InstallStatus_Uninstalling:        pb.InstallStatus_Discarded,
+	},
+}
+
+type deviceService struct {
+	pb.UnimplementedDeviceServiceServer
+	s *Server
+}
+
+func (d *deviceService) List(ctx context.Context, req *pb.DeviceListRequest) (*pb.DeviceListResponse, error) {
+	d.s.mu.Lock()
+	defer d.s.mu.Unlock()
+
+	devs := make([]*pb.Device, 0)
+	for _, dev := range d.s.devices {
+		if dev.InstallStatus == req.InstallStatus {
+			devs = append(devs, proto.Clone(dev).(*pb.Device))
+		}
+	}
+
+	return &pb.DeviceListResponse{ResultCode: pb.ResponseCode_SUCCESS, Devices: devs}, nil
+}
+
+func (d *deviceService) FilterList(req *pb.DeviceFilterListRequest, stream pb.DeviceService_FilterListServer) error {
+	d.s.mu.Lock()
+	devs := make([]*pb.Device, 0)
+	for _, dev := range d.s.devices {
+		if req.InstallStatus != nil && dev.InstallStatus != req.GetInstallStatusValue() {
+			continue
+		}
+
+		if req.GroupId != nil && dev.GroupId != req.GetGroupIdValue() {
+			continue
+		}
+
+		devs = append(devs, proto.Clone(dev).(*pb.Device))
+	}
+	failAfter, failErr := d.s.filterListFailAfter, d.s.filterListErr
+	d.s.mu.Unlock()
+
+	sort.Slice(devs, func(i, j int) bool {
+		return devs[i].Devid < devs[j].Devid
+	})
+
+	for i, dev := range devs {
+		if failErr != nil && i == failAfter {
+			return failErr
+		}
+
+		if err := stream.Send(dev); err != nil {
+			return err
+		}
+	}
+
+	if failErr != nil {
+		return failErr
+	}
+
+	return nil
+}
+
+func (d *deviceService) Detail(ctx context.Context, req *pb.DeviceRequest) (*pb.DeviceResponse, error) {
+	dev := d.s.Device(req.Devid)
+	if dev == nil {
+		return &pb.DeviceResponse{ResultCode: pb.ResponseCode_NON_EXIST}, nil
+	}
+
+	return &pb.DeviceResponse{ResultCode: pb.ResponseCode_SUCCESS, Devices: []*pb.Device{dev}}, nil
+}
+
+func (d *deviceService) Update(ctx context.Context, req *pb.Device) (*pb.DeviceResponse, error) {
+	d.s.mu.Lock()
+	defer d.s.mu.Unlock()
+
+	if _, ok := d.s.devices[req.Devid]; !ok {
+		return &pb.DeviceResponse{ResultCode: pb.ResponseCode_NON_EXIST}, nil
+	}
+
+	d.s.devices[req.Devid] = proto.Clone(req).(*pb.Device)
+
+	return &pb.DeviceResponse{ResultCode: pb.ResponseCode_SUCCESS, Devices: []*pb.Device{proto.Clone(req).(*pb.Device)}}, nil
+}
+
+func (d *deviceService) UpdateInfo(ctx context.Context, req *pb.DeviceInfoUpdateRequest) (*pb.DeviceResponse, error) {
+	return d.update(req.Devid, func(dev *pb.Device) {
+		if req.Alias != nil {
+			dev.Alias = req.GetAliasValue()
+		}
+		if req.GroupId != nil {
+			dev.GroupId = req.GetGroupIdValue()
+		}
+		if req.Latitude != nil {
+			dev.Latitude = req.GetLatitudeValue()
+		}
+		if req.Longitude != nil {
+			dev.Longitude = req.GetLongitudeValue()
+		}
+		if req.Installer != nil {
+			dev.Installer = req.GetInstallerValue()
+		}
+		if req.InstallDate != nil {
+			dev.InstallDate = req.GetInstallDateValue()
+		}
+		if req.DevType != nil {
+			dev.DevType = req.GetDevTypeValue()
+		}
+		if req.AppFwVer != nil {
+			dev.AppFwVer = req.GetAppFwVerValue()
+		}
+		if req.LoraFwVer != nil {
+			dev.LoraFwVer = req.GetLoraFwVerValue()
+		}
+		if req.Period != nil {
+			dev.Period = req.GetPeriodValue()
+		}
+		if req.RecogType != nil {
+			dev.RecogType = req.GetRecogTypeValue()
+		}
+		if req.InstallSession != nil {
+			dev.InstallSessionKey = req.GetInstallSessionValue()
+		}
+	})
+}
+
+func (d *deviceService) UpdateStatus(ctx context.Context, req *pb.DeviceStatusUpdateRequest) (*pb.DeviceResponse, error) {
+	return d.update(req.Devid, func(dev *pb.Device) {
+		if req.InstallStatus != nil {
+			dev.InstallStatus = req.GetInstallStatusValue()
+		}
+		if req.AlarmStatus != nil {
+			dev.IsAlarmed = req.GetIsAlarmedValue()
+		}
+		if req.AlarmDate != nil {
+			dev.AlarmDate = req.GetAlarmDateValue()
+		}
+		if req.IsAlive != nil {
+			dev.IsAlive = req.GetIsAliveValue()
+		}
+		if req.UpdateDate != nil {
+			dev.UpdateDate = req.GetUpdateDateValue()
+		}
+		if req.Battery != nil {
+			dev.Battery = req.GetBatteryValue()
+		}
+		if req.Temperature != nil {
+			dev.Temperature = req.GetTemperatureValue()
+		}
+		if req.Rssi != nil {
+			dev.Rssi = req.GetRssiValue()
+		}
+		if req.AccX != nil {
+			dev.AccXMg = req.GetAccXMgValue()
+		}
+		if req.AccY != nil {
+			dev.AccYMg = req.GetAccYMgValue()
+		}
+		if req.AccZ != nil {
+			dev.AccZMg = req.GetAccZMgValue()
+		}
+		if req.IsDeviceOk != nil {
+			dev.IsDeviceOk = req.GetIsDeviceOkValue()
+		}
+	})
+}
+
+func (d *deviceService) UpdateConfig(ctx context.Context, req *pb.DeviceConfigUpdateRequest) (*pb.DeviceResponse, error) {
+	return d.update(req.Devid, func(dev *pb.Device) {
+		if req.SensorRange != nil {
+			dev.SensorRange = req.GetSensorRangeValue()
+		}
+		if req.IntThreshold != nil {
+			dev.IntThresholdMg = req.GetIntThresholdValue()
+		}
+		if req.DecisionThreshold != nil {
+			dev.DecisionThresholdMg = req.GetDecisionThresholdValue()
+		}
+		if req.SampleRate != nil {
+			dev.SampleRate = req.GetSampleRateValue()
+		}
+		if req.WaveBlocks != nil {
+			dev.WaveBlocks = req.GetWaveBlocksValue()
+		}
+		if req.IsNotifEnabled != nil {
+			dev.IsNotifEnabled = req.GetIsNotifEnabledValue()
+		}
+		if req.RecogParam_0 != nil {
+			dev.RecogParam_0 = req.GetRecogParam_0Value()
+		}
+		if req.RecogParam_1 != nil {
+			dev.RecogParam_1 = req.GetRecogParam_1Value()
+		}
+		if req.RecogParam_2 != nil {
+			dev.RecogParam_2 = req.GetRecogParam_2Value()
+		}
+		if req.MuteDate != nil {
+			dev.MuteDate = req.GetMuteDateValue()
+		}
+	})
+}
+
+func (d *deviceService) update(devid string, apply func(*pb.Device)) (*pb.DeviceResponse, error) {
+	d.s.mu.Lock()
+	defer d.s.mu.Unlock()
+
+	dev, ok := d.s.devices[devid]
+	if !ok {
+		return &pb.DeviceResponse{ResultCode: pb.ResponseCode_NON_EXIST}, nil
+	}
+
+	apply(dev)
+	dev.Devid = devid
+	dev.UpdateDate = ptypes.TimestampNow()
+
+	return &pb.DeviceResponse{
+		ResultCode: pb.ResponseCode_SUCCESS,
+		Devices:    []*pb.Device{proto.Clone(dev).(*pb.Device)},
+	}, nil
+}
+
+// transit changes install status of device by request.
+// apply is called with locked state only if transition is permitted.
+func (d *deviceService) transit(request, devid string, apply func(*pb.Device) pb.ResponseCode) pb.ResponseCode {
+	d.s.mu.Lock()
+	defer d.s.mu.Unlock()
+
+	dev, ok := d.s.devices[devid]
+	if !ok {
+		return pb.ResponseCode_NON_EXIST
+	}
+
+	next, ok := installTransitions[request][dev.InstallStatus]
+	if !ok {
+		return pb.ResponseCode_NOT_ALLOWED
+	}
+
+	if apply != nil {
+		if code := apply(dev); code != pb.ResponseCode_SUCCESS {
+			return code
+		}
+	}
+
+	dev.InstallStatus = next
+
+	return pb.ResponseCode_SUCCESS
+}
+
+func (d *deviceService) PrepareInstall(ctx context.Context, req *pb.PrepareInstallRequest) (*pb.PrepareInstallResponse, error) {
+	sessionKey := newKey(32)
+
+	code := d.transit("PrepareInstall", req.Devid, func(dev *pb.Device) pb.ResponseCode {
+		dev.Alias = req.Alias
+		dev.Latitude = req.Latitude
+		dev.Longitude = req.Longitude
+		dev.GroupId = req.GroupId
+		dev.Installer = req.Installer
+		dev.InstallDate = ptypes.TimestampNow()
+		dev.InstallSessionKey = sessionKey
+		return pb.ResponseCode_SUCCESS
+	})
+
+	resp := &pb.PrepareInstallResponse{ResponseCode: code, Devid: req.Devid}
+	if code == pb.ResponseCode_SUCCESS {
+		resp.InstallSessionKey = sessionKey
+	}
+
+	return resp, nil
+}
+
+func (d *deviceService) WaitCompleteInstall(ctx context.Context, req *pb.WaitCompleteInstallRequest) (*pb.WaitCompleteInstallResponse, error) {
+	code := d.transit("WaitCompleteInstall", req.Devid, nil)
+	return &pb.WaitCompleteInstallResponse{ResponseCode: code, Devid: req.Devid}, nil
+}
+
+func (d *deviceService) CompleteInstall(ctx context.Context, req *pb.CompleteInstallRequest) (*pb.CompleteInstallResponse, error) {
+	code := d.transit("CompleteInstall", req.Devid, func(dev *pb.Device) pb.ResponseCode {
+		if dev.InstallSessionKey != req.InstallSessionKey {
+			return pb.ResponseCode_NOT_ALLOWED
+		}
+
+		// Reset alarm and detection config of new install session.
+		dev.IsAlarmed = false
+		dev.AlarmDate = nil
+		dev.MuteDate = nil
+		dev.RecogType = pb.RecogType_RecogV4
+		dev.SensorRange = pb.SensorRangeType_Gravity2
+		dev.SampleRate = 100
+		dev.WaveBlocks = 1
+		dev.RecogParam_0 = 12
+		dev.RecogParam_1 = 0.6
+		dev.RecogParam_2 = 8
+		return pb.ResponseCode_SUCCESS
+	})
+
+	return &pb.CompleteInstallResponse{
+		ResponseCode:      code,
+		Devid:             req.Devid,
+		InstallSessionKey: req.InstallSessionKey,
+	}, nil
+}
+
+func (d *deviceService) Uninstalling(ctx context.Context, req *pb.UninstallingRequest) (*pb.UninstallingResponse, error) {
+	code := d.transit("Uninstalling", req.Devid, nil)
+	return &pb.UninstallingResponse{ResponseCode: code, Devid: req.Devid}, nil
+}
+
+func (d *deviceService) Uninstall(ctx context.Context, req *pb.UninstallRequest) (*pb.UninstallResponse, error) {
+	code := d.transit("Uninstall", req.Devid, func(dev *pb.Device) pb.ResponseCode {
+		dev.Alias = ""
+		dev.Latitude = 0
+		dev.Longitude = 0
+		dev.Installer = ""
+		dev.InstallDate = nil
+		return pb.ResponseCode_SUCCESS
+	})
+
+	return &pb.UninstallResponse{ResponseCode: code, Devid: req.Devid}, nil
+}
+
+func (d *deviceService) Discard(ctx context.Context, req *pb.DiscardRequest) (*pb.DiscardResponse, error) {
+	code := d.transit("Discard", req.Devid, nil)
+	return &pb.DiscardResponse{ResponseCode: code, Devid: req.Devid}, nil
+}
@@ -0,0 +1,221 @@
+package fake_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+	"github.com/rootwarp/ino-vibe-go-sdk/alert"
+	"github.com/rootwarp/ino-vibe-go-sdk/device"
+	"github.com/rootwarp/ino-vibe-go-sdk/fake"
+	"github.com/rootwarp/ino-vibe-go-sdk/fake/faketest"
+	"github.com/rootwarp/ino-vibe-go-sdk/group"
+	"github.com/rootwarp/ino-vibe-go-sdk/thingplug"
+	"github.com/rootwarp/ino-vibe-go-sdk/user"
+	"github.com/rootwarp/ino-vibe-go-sdk/wave"
+)
+
+const testDevice = "000000030000000000000001"
+
+func TestDeviceInstallLifecycle(t *testing.T) {
+	srv, conn := faketest.NewServer(t)
+	srv.AddDevice(&pb.Device{Devid: testDevice, InstallStatus: pb.InstallStatus_Initial})
+
+	cli, _ := device.NewClient(device.WithConn(conn))
+	ctx := context.Background()
+
+	prepResp, err := cli.PrepareInstall(ctx, &pb.PrepareInstallRequest{Devid: testDevice, Alias: "test-alias", GroupId: "group-1"})
+	assert.Nil(t, err)
+	assert.Equal(t, pb.ResponseCode_SUCCESS, prepResp.ResponseCode)
+	assert.NotEmpty(t, prepResp.InstallSessionKey)
+	assert.Equal(t, pb.InstallStatus_Requested, srv.Device(testDevice).InstallStatus)
+
+	// Server rejects invalid transition even if client does not validate it.
+	again, _ := pb.NewDeviceServiceClient(conn).PrepareInstall(ctx, &pb.PrepareInstallRequest{Devid: testDevice})
+	assert.Equal(t, pb.ResponseCode_NOT_ALLOWED, again.ResponseCode)
+
+	waitResp, err := cli.WaitCompleteInstall(ctx, &pb.WaitCompleteInstallRequest{Devid: testDevice})
+	assert.Nil(t, err)
+	assert.Equal(t, pb.ResponseCode_SUCCESS, waitResp.ResponseCode)
+
+	wrongKey, _ := cli.CompleteInstall(ctx, &pb.CompleteInstallRequest{Devid: testDevice, InstallSessionKey: "wrong"})
+	assert.Equal(t, pb.ResponseCode_NOT_ALLOWED, wrongKey.ResponseCode)
+
+	completeResp, err := cli.CompleteInstall(ctx, &pb.CompleteInstallRequest{Devid: testDevice, InstallSessionKey: prepResp.InstallSessionKey})
+	assert.Nil(t, err)
+	assert.Equal(t, pb.ResponseCode_SUCCESS, completeResp.ResponseCode)
+
+	dev := srv.Device(testDevice)
+	assert.Equal(t, pb.InstallStatus_Installed, dev.InstallStatus)
+	assert.Equal(t, "test-alias", dev.Alias)
+	assert.Equal(t, pb.RecogType_RecogV4, dev.RecogType)
+	assert.Equal(t, float64(100), dev.SampleRate)
+
+	uninstallingResp, _ := cli.Uninstalling(ctx, &pb.UninstallingRequest{Devid: testDevice})
+	assert.Equal(t, pb.ResponseCode_SUCCESS, uninstallingResp.ResponseCode)
+
+	uninstallResp, _ := cli.Uninstall(ctx, &pb.UninstallRequest{Devid: testDevice})
+	assert.Equal(t, pb.ResponseCode_SUCCESS, uninstallResp.ResponseCode)
+
+	dev = srv.Device(testDevice)
+	assert.Equal(t, pb.InstallStatus_Initial, dev.InstallStatus)
+	assert.Equal(t, "", dev.Alias)
+
+	discardResp, _ := pb.NewDeviceServiceClient(conn).Discard(ctx, &pb.DiscardRequest{Devid: testDevice})
+	assert.Equal(t, pb.ResponseCode_NOT_ALLOWED, discardResp.ResponseCode)
+
+	missing, _ := pb.NewDeviceServiceClient(conn).PrepareInstall(ctx, &pb.PrepareInstallRequest{Devid: "unknown"})
+	assert.Equal(t, pb.ResponseCode_NON_EXIST, missing.ResponseCode)
+}
+
+func TestDeviceListAndUpdate(t *testing.T) {
+	srv, conn := faketest.NewServer(t)
+	srv.AddDevice(&pb.Device{Devid: "dev-1", GroupId: "group-1", InstallStatus: pb.InstallStatus_Installed})
+	srv.AddDevice(&pb.Device{Devid: "dev-2", GroupId: "group-1", InstallStatus: pb.InstallStatus_Initial})
+	srv.AddDevice(&pb.Device{Devid: "dev-3", GroupId: "group-2", InstallStatus: pb.InstallStatus_Installed})
+
+	cli, _ := device.NewClient(device.WithConn(conn))
+	ctx := context.Background()
+
+	listResp, err := cli.List(ctx, pb.InstallStatus_Installed)
+	assert.Nil(t, err)
+	assert.Len(t, listResp.Devices, 2)
+
+	devs, err := cli.FilterList(ctx, &pb.DeviceFilterListRequest{
+		GroupId:       &pb.DeviceFilterListRequest_GroupIdValue{GroupIdValue: "group-1"},
+		InstallStatus: &pb.DeviceFilterListRequest_InstallStatusValue{InstallStatusValue: pb.InstallStatus_Installed},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, devs, 1)
+	assert.Equal(t, "dev-1", devs[0].Devid)
+
+	updateResp, err := cli.UpdateStatus(ctx, &pb.DeviceStatusUpdateRequest{
+		Devid:   "dev-1",
+		Battery: &pb.DeviceStatusUpdateRequest_BatteryValue{BatteryValue: 80},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, pb.ResponseCode_SUCCESS, updateResp.ResultCode)
+
+	detailResp, err := cli.Detail(ctx, "dev-1")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(80), detailResp.Devices[0].Battery)
+
+	detailResp, _ = cli.Detail(ctx, "unknown")
+	assert.Equal(t, pb.ResponseCode_NON_EXIST, detailResp.ResultCode)
+}
+
+func TestGroup(t *testing.T) {
+	srv, conn := faketest.NewServer(t)
+	srv.AddGroup(&pb.Group{Groupid: "root", Name: "Root"})
+	srv.AddGroup(&pb.Group{Groupid: "child", Name: "Child", ParentId: "root"})
+	srv.AddGroup(&pb.Group{Groupid: "leaf", Name: "Leaf", ParentId: "child"})
+	srv.AddMember("root", &pb.User{UserId: "user-1", Email: "root@example.com"})
+
+	cli, _ := group.NewClient(group.WithConn(conn))
+	ctx := context.Background()
+
+	groups, err := cli.List(ctx, "root")
+	assert.Nil(t, err)
+	assert.Len(t, groups, 1)
+	assert.Equal(t, "Root", groups[0].Name)
+	assert.Equal(t, "Child", groups[0].Children[0].Name)
+	assert.Equal(t, "Leaf", groups[0].Children[0].Children[0].Name)
+
+	name, err := cli.GetName(ctx, "child")
+	assert.Nil(t, err)
+	assert.Equal(t, "Child", name)
+
+	_, err = cli.GetName(ctx, "unknown")
+	assert.Equal(t, group.ErrGroupNonExist, err)
+
+	id, err := cli.GetID(ctx, "Leaf")
+	assert.Nil(t, err)
+	assert.Equal(t, "leaf", id)
+
+	emails, err := cli.GetParentUsers(ctx, "leaf")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"root@example.com"}, emails)
+
+	members, err := cli.GetMembers(ctx, "root")
+	assert.Nil(t, err)
+	assert.Equal(t, []user.User{{UserID: "user-1", Email: "root@example.com"}}, members)
+}
+
+func TestAlertList(t *testing.T) {
+	srv, conn := faketest.NewServer(t)
+	for i, devid := range []string{"dev-1", "dev-2", "dev-1"} {
+		srv.AddAlert(&pb.AlertListItem{
+			Alertid: string(rune('a' + i)),
+			Devid:   devid,
+			Issued:  &timestamp.Timestamp{Seconds: int64(1000 + i)},
+		})
+	}
+
+	cli, _ := alert.NewClient(alert.WithConn(conn))
+	defer cli.Close()
+
+	resp, err := cli.List(context.Background(), &pb.AlertListRequest{Search: &pb.AlertListRequest_Devid{Devid: "dev-1"}})
+	assert.Nil(t, err)
+	assert.Len(t, resp.Alerts, 2)
+	assert.Equal(t, "c", resp.Alerts[0].Alertid)
+	assert.Equal(t, "a", resp.Alerts[1].Alertid)
+}
+
+func TestWave(t *testing.T) {
+	srv, conn := faketest.NewServer(t)
+	srv.AddWave(&pb.WaveDetailItem{Waveid: "wave-1", Devid: testDevice, Created: ptypes.TimestampNow()})
+	srv.AddWave(&pb.WaveDetailItem{Waveid: "wave-2", Devid: "other"})
+
+	cli, _ := wave.NewClient(wave.WithConn(conn))
+	defer cli.Close()
+
+	ctx := context.Background()
+
+	waves, err := cli.List(ctx, testDevice, 0, 10)
+	assert.Nil(t, err)
+	assert.Len(t, waves, 1)
+	assert.Equal(t, "wave-1", waves[0].Waveid)
+
+	detailResp, err := cli.Detail(ctx, &pb.WaveDetailRequest{Waveid: "wave-2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "other", detailResp.Wave.Devid)
+}
+
+func TestUserDeviceToken(t *testing.T) {
+	_, conn := faketest.NewServer(t)
+
+	cli, _ := user.NewClient(user.WithConn(conn))
+
+	tokens, err := cli.GetDeviceToken("test-user")
+	assert.Nil(t, err)
+	assert.Len(t, tokens, 0)
+
+	assert.Nil(t, cli.RegisterDeviceToken("user-1", "test-user", "token-1"))
+	assert.Nil(t, cli.RegisterDeviceToken("user-1", "test-user", "token-1"))
+
+	tokens, err = cli.GetDeviceToken("test-user")
+	assert.Nil(t, err)
+	assert.Equal(t, []user.DeviceToken{{Token: "token-1"}}, tokens)
+}
+
+func TestThingplug(t *testing.T) {
+	srv, conn := faketest.NewServer(t)
+	srv.AddDevice(&pb.Device{Devid: testDevice})
+
+	cli, _ := thingplug.NewClient(thingplug.WithConn(conn))
+	defer cli.Close()
+
+	ctx := context.Background()
+
+	assert.Nil(t, cli.Reset(ctx, testDevice))
+	assert.Nil(t, cli.BaseReset(ctx, testDevice))
+
+	assert.Equal(t, []fake.Command{
+		{Name: fake.CommandReset, Devid: testDevice},
+		{Name: fake.CommandBaseReset, Devid: testDevice},
+	}, srv.Commands())
+}
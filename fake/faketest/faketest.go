@@ -0,0 +1,33 @@
+// Package faketest starts fake server for tests.
+// It is separated from package fake so that importers of fake do not link package testing.
+package faketest
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/rootwarp/ino-vibe-go-sdk/fake"
+)
+
+// NewServer starts server and dials it for test.
+// Connection and server are closed when test finishes.
+func NewServer(t testing.TB) (*fake.Server, *grpc.ClientConn) {
+	t.Helper()
+
+	srv := fake.NewServer()
+
+	conn, err := srv.Dial(context.Background())
+	if err != nil {
+		srv.Close()
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		srv.Close()
+	})
+
+	return srv, conn
+}
@@ -0,0 +1,218 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+type groupService struct {
+	pb.UnimplementedGroupServiceServer
+	s *Server
+}
+
+// descendants returns selected group and all of its child groups.
+// Every group is returned if groupID is empty.
+func (g *groupService) descendants(groupID string) []*pb.Group {
+	groups := make([]*pb.Group, 0)
+
+	if groupID == "" {
+		for _, group := range g.s.groups {
+			groups = append(groups, proto.Clone(group).(*pb.Group))
+		}
+		return groups
+	}
+
+	root, ok := g.s.groups[groupID]
+	if !ok {
+		return groups
+	}
+
+	visited := map[string]bool{}
+	queue := []*pb.Group{root}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if visited[current.Groupid] {
+			continue
+		}
+		visited[current.Groupid] = true
+
+		groups = append(groups, proto.Clone(current).(*pb.Group))
+		queue = append(queue, g.children(current.Groupid)...)
+	}
+
+	return groups
+}
+
+func (g *groupService) children(groupID string) []*pb.Group {
+	children := make([]*pb.Group, 0)
+	for _, group := range g.s.groups {
+		if group.ParentId == groupID && group.Groupid != groupID {
+			children = append(children, group)
+		}
+	}
+	return children
+}
+
+func (g *groupService) List(req *pb.GroupRequest, stream pb.GroupService_ListServer) error {
+	g.s.mu.Lock()
+	groups := g.descendants(req.Groupid)
+	g.s.mu.Unlock()
+
+	for _, group := range groups {
+		if err := stream.Send(group); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *groupService) Detail(ctx context.Context, req *pb.GroupRequest) (*pb.GroupResponse, error) {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+
+	group, ok := g.s.groups[req.Groupid]
+	if !ok {
+		return &pb.GroupResponse{ResultCode: pb.ResponseCode_NON_EXIST}, nil
+	}
+
+	return &pb.GroupResponse{ResultCode: pb.ResponseCode_SUCCESS, Groups: []*pb.Group{proto.Clone(group).(*pb.Group)}}, nil
+}
+
+func (g *groupService) Childs(ctx context.Context, req *pb.GroupRequest) (*pb.GroupResponse, error) {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+
+	if _, ok := g.s.groups[req.Groupid]; !ok {
+		return &pb.GroupResponse{ResultCode: pb.ResponseCode_NON_EXIST}, nil
+	}
+
+	children := g.children(req.Groupid)
+	groups := make([]*pb.Group, len(children))
+	for i, child := range children {
+		groups[i] = proto.Clone(child).(*pb.Group)
+	}
+
+	return &pb.GroupResponse{ResultCode: pb.ResponseCode_SUCCESS, Groups: groups}, nil
+}
+
+func (g *groupService) memberResponse(groups []*pb.Group) *pb.MemberResponse {
+	resp := &pb.MemberResponse{ResponseCode: pb.ResponseCode_SUCCESS}
+	for _, group := range groups {
+		for _, user := range g.s.members[group.Groupid] {
+			resp.Emails = append(resp.Emails, user.Email)
+			resp.Users = append(resp.Users, proto.Clone(user).(*pb.User))
+		}
+	}
+	return resp
+}
+
+func (g *groupService) NestedUsers(ctx context.Context, req *pb.GroupRequest) (*pb.MemberResponse, error) {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+
+	if _, ok := g.s.groups[req.Groupid]; !ok {
+		return &pb.MemberResponse{ResponseCode: pb.ResponseCode_NON_EXIST}, nil
+	}
+
+	return g.memberResponse(g.descendants(req.Groupid)), nil
+}
+
+func (g *groupService) ParentUsers(ctx context.Context, req *pb.GroupRequest) (*pb.MemberResponse, error) {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+
+	group, ok := g.s.groups[req.Groupid]
+	if !ok {
+		return &pb.MemberResponse{ResponseCode: pb.ResponseCode_NON_EXIST}, nil
+	}
+
+	parents := make([]*pb.Group, 0)
+	visited := map[string]bool{group.Groupid: true}
+	for {
+		parent, ok := g.s.groups[group.ParentId]
+		if !ok || visited[parent.Groupid] {
+			break
+		}
+
+		visited[parent.Groupid] = true
+		parents = append(parents, parent)
+		group = parent
+	}
+
+	return g.memberResponse(parents), nil
+}
+
+func (g *groupService) FindByID(ctx context.Context, req *pb.GroupFindRequest) (*pb.GroupResponse, error) {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+
+	resp := &pb.GroupResponse{ResultCode: pb.ResponseCode_SUCCESS}
+	for _, name := range req.Names {
+		for _, group := range g.s.groups {
+			if group.Name == name {
+				resp.Groups = append(resp.Groups, proto.Clone(group).(*pb.Group))
+			}
+		}
+	}
+
+	return resp, nil
+}
+
+func (g *groupService) Members(ctx context.Context, req *pb.GroupRequest) (*pb.MemberResponse, error) {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+
+	return g.memberResponse([]*pb.Group{{Groupid: req.Groupid}}), nil
+}
+
+func (g *groupService) Create(ctx context.Context, req *pb.Group) (*pb.GroupResponse, error) {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+
+	if req.ParentId != "" {
+		if _, ok := g.s.groups[req.ParentId]; !ok {
+			return &pb.GroupResponse{ResultCode: pb.ResponseCode_NON_EXIST}, nil
+		}
+	}
+
+	group := proto.Clone(req).(*pb.Group)
+	group.Groupid = newKey(16)
+	g.s.groups[group.Groupid] = group
+
+	return &pb.GroupResponse{ResultCode: pb.ResponseCode_SUCCESS, Groups: []*pb.Group{proto.Clone(group).(*pb.Group)}}, nil
+}
+
+func (g *groupService) Update(ctx context.Context, req *pb.Group) (*pb.GroupResponse, error) {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+
+	if _, ok := g.s.groups[req.Groupid]; !ok {
+		return &pb.GroupResponse{ResultCode: pb.ResponseCode_NON_EXIST}, nil
+	}
+
+	g.s.groups[req.Groupid] = proto.Clone(req).(*pb.Group)
+
+	return &pb.GroupResponse{ResultCode: pb.ResponseCode_SUCCESS, Groups: []*pb.Group{proto.Clone(req).(*pb.Group)}}, nil
+}
+
+func (g *groupService) Delete(ctx context.Context, req *pb.GroupRequest) (*pb.GroupResponse, error) {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+
+	if _, ok := g.s.groups[req.Groupid]; !ok {
+		return &pb.GroupResponse{ResultCode: pb.ResponseCode_NON_EXIST}, nil
+	}
+
+	for _, group := range g.descendants(req.Groupid) {
+		delete(g.s.groups, group.Groupid)
+		delete(g.s.members, group.Groupid)
+	}
+
+	return &pb.GroupResponse{ResultCode: pb.ResponseCode_SUCCESS}, nil
+}
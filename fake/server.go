@@ -0,0 +1,178 @@
+// Package fake provides in-process Ino-Vibe gRPC server with in-memory state.
+//
+// Server implements every service of Ino-Vibe API and is exposed through bufconn,
+// so SDK clients can be tested without network access.
+//
+//	srv := fake.NewServer()
+//	defer srv.Close()
+//
+//	srv.AddDevice(&pb.Device{Devid: "test-device"})
+//
+//	conn, _ := srv.Dial(ctx)
+//	cli, _ := device.NewClient(device.WithConn(conn))
+package fake
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+const bufSize = 1024 * 1024
+
+// Server is in-process Ino-Vibe API server.
+type Server struct {
+	mu sync.Mutex
+
+	devices      map[string]*pb.Device
+	groups       map[string]*pb.Group
+	members      map[string][]*pb.User
+	alerts       []*pb.AlertListItem
+	waves        map[string]*pb.WaveDetailItem
+	deviceTokens map[string][]*pb.DeviceToken
+	commands     []Command
+
+	filterListFailAfter int
+	filterListErr       error
+
+	listener *bufconn.Listener
+	server   *grpc.Server
+}
+
+// Command is control request which thingplug service received.
+type Command struct {
+	Name  string
+	Devid string
+}
+
+// NewServer creates and starts new server with empty state.
+func NewServer() *Server {
+	s := &Server{
+		devices:      map[string]*pb.Device{},
+		groups:       map[string]*pb.Group{},
+		members:      map[string][]*pb.User{},
+		alerts:       []*pb.AlertListItem{},
+		waves:        map[string]*pb.WaveDetailItem{},
+		deviceTokens: map[string][]*pb.DeviceToken{},
+		listener:     bufconn.Listen(bufSize),
+		server:       grpc.NewServer(),
+	}
+
+	pb.RegisterDeviceServiceServer(s.server, &deviceService{s: s})
+	pb.RegisterGroupServiceServer(s.server, &groupService{s: s})
+	pb.RegisterAlertServiceServer(s.server, &alertService{s: s})
+	pb.RegisterWaveServiceServer(s.server, &waveService{s: s})
+	pb.RegisterUserServiceServer(s.server, &userService{s: s})
+	pb.RegisterThingplugServiceServer(s.server, &thingplugService{s: s})
+
+	go func() {
+		_ = s.server.Serve(s.listener)
+	}()
+
+	return s
+}
+
+// Dial creates client connection to server.
+// Connection should be closed by caller.
+func (s *Server) Dial(ctx context.Context) (*grpc.ClientConn, error) {
+	return grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return s.listener.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+}
+
+// Close stops server.
+func (s *Server) Close() {
+	s.server.Stop()
+	_ = s.listener.Close()
+}
+
+// AddDevice adds or replaces device.
+func (s *Server) AddDevice(dev *pb.Device) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.devices[dev.Devid] = proto.Clone(dev).(*pb.Device)
+}
+
+// Device returns copy of current device state or nil if it does not exist.
+func (s *Server) Device(devid string) *pb.Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dev, ok := s.devices[devid]
+	if !ok {
+		return nil
+	}
+
+	return proto.Clone(dev).(*pb.Device)
+}
+
+// AddGroup adds or replaces group.
+func (s *Server) AddGroup(group *pb.Group) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.groups[group.Groupid] = proto.Clone(group).(*pb.Group)
+}
+
+// AddMember joins user into group.
+func (s *Server) AddMember(groupID string, user *pb.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.members[groupID] = append(s.members[groupID], proto.Clone(user).(*pb.User))
+}
+
+// AddAlert adds alert.
+func (s *Server) AddAlert(alert *pb.AlertListItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.alerts = append(s.alerts, proto.Clone(alert).(*pb.AlertListItem))
+}
+
+// AddWave adds or replaces wave.
+func (s *Server) AddWave(wave *pb.WaveDetailItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.waves[wave.Waveid] = proto.Clone(wave).(*pb.WaveDetailItem)
+}
+
+// FailFilterList makes FilterList stream fail with err after sending count devices.
+// Devices are streamed in order of devid. Nil err restores normal stream.
+func (s *Server) FailFilterList(count int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.filterListFailAfter = count
+	s.filterListErr = err
+}
+
+// Commands returns control requests received by thingplug service in order.
+func (s *Server) Commands() []Command {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	commands := make([]Command, len(s.commands))
+	copy(commands, s.commands)
+
+	return commands
+}
+
+func newKey(size int) string {
+	buf := make([]byte, size)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,44 @@
+package fake
+
+import (
+	"context"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+// Thingplug command names.
+const (
+	CommandReset     = "Reset"
+	CommandPowerOff  = "PowerOff"
+	CommandBaseReset = "BaseReset"
+)
+
+type thingplugService struct {
+	pb.UnimplementedThingplugServiceServer
+	s *Server
+}
+
+func (t *thingplugService) command(name, devid string) *pb.ThingplugDeviceResponse {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	if _, ok := t.s.devices[devid]; !ok {
+		return &pb.ThingplugDeviceResponse{ResponseCode: pb.ResponseCode_NON_EXIST}
+	}
+
+	t.s.commands = append(t.s.commands, Command{Name: name, Devid: devid})
+
+	return &pb.ThingplugDeviceResponse{ResponseCode: pb.ResponseCode_SUCCESS}
+}
+
+func (t *thingplugService) Reset(ctx context.Context, req *pb.ThingplugDeviceRequest) (*pb.ThingplugDeviceResponse, error) {
+	return t.command(CommandReset, req.Devid), nil
+}
+
+func (t *thingplugService) PowerOff(ctx context.Context, req *pb.ThingplugDeviceRequest) (*pb.ThingplugDeviceResponse, error) {
+	return t.command(CommandPowerOff, req.Devid), nil
+}
+
+func (t *thingplugService) BaseReset(ctx context.Context, req *pb.ThingplugDeviceRequest) (*pb.ThingplugDeviceResponse, error) {
+	return t.command(CommandBaseReset, req.Devid), nil
+}
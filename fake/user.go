@@ -0,0 +1,46 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+type userService struct {
+	pb.UnimplementedUserServiceServer
+	s *Server
+}
+
+func (u *userService) RegisterDeviceToken(ctx context.Context, req *pb.RegisterDeviceTokenRequest) (*pb.RegisterDeviceTokenResponse, error) {
+	u.s.mu.Lock()
+	defer u.s.mu.Unlock()
+
+	for _, token := range u.s.deviceTokens[req.Username] {
+		if token.Token == req.DeviceToken {
+			return &pb.RegisterDeviceTokenResponse{ResponseCode: pb.ResponseCode_SUCCESS}, nil
+		}
+	}
+
+	u.s.deviceTokens[req.Username] = append(u.s.deviceTokens[req.Username], &pb.DeviceToken{Token: req.DeviceToken})
+
+	return &pb.RegisterDeviceTokenResponse{ResponseCode: pb.ResponseCode_SUCCESS}, nil
+}
+
+func (u *userService) GetDeviceToken(ctx context.Context, req *pb.GetDeviceTokenRequest) (*pb.GetDeviceTokenResponse, error) {
+	u.s.mu.Lock()
+	defer u.s.mu.Unlock()
+
+	tokens, ok := u.s.deviceTokens[req.Username]
+	if !ok {
+		return &pb.GetDeviceTokenResponse{ResponseCode: pb.ResponseCode_NON_EXIST, Username: req.Username}, nil
+	}
+
+	resp := &pb.GetDeviceTokenResponse{ResponseCode: pb.ResponseCode_SUCCESS, Username: req.Username}
+	for _, token := range tokens {
+		resp.DeviceTokens = append(resp.DeviceTokens, proto.Clone(token).(*pb.DeviceToken))
+	}
+
+	return resp, nil
+}
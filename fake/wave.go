@@ -0,0 +1,75 @@
+package fake
+
+import (
+	"context"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+type waveService struct {
+	pb.UnimplementedWaveServiceServer
+	s *Server
+}
+
+// List streams latest waves first.
+func (w *waveService) List(req *pb.WaveListRequest, stream pb.WaveService_ListServer) error {
+	w.s.mu.Lock()
+	waves := make([]*pb.WaveDetailItem, 0)
+	for _, wave := range w.s.waves {
+		switch filter := req.Filter.(type) {
+		case *pb.WaveListRequest_Devid:
+			if wave.Devid != filter.Devid {
+				continue
+			}
+		case *pb.WaveListRequest_Groupid:
+			if wave.Groupid != filter.Groupid {
+				continue
+			}
+		}
+
+		created := wave.GetCreated().GetSeconds()
+		if req.TimeFrom != nil && created < req.GetTimeFromValue().GetSeconds() {
+			continue
+		}
+
+		if req.TimeTo != nil && created > req.GetTimeToValue().GetSeconds() {
+			continue
+		}
+
+		waves = append(waves, proto.Clone(wave).(*pb.WaveDetailItem))
+	}
+	w.s.mu.Unlock()
+
+	sort.SliceStable(waves, func(i, j int) bool {
+		return waves[i].GetCreated().GetSeconds() > waves[j].GetCreated().GetSeconds()
+	})
+
+	sent := 0
+	for i := int(req.Offset); i < len(waves); i++ {
+		if req.MaxCount > 0 && sent >= int(req.MaxCount) {
+			break
+		}
+
+		if err := stream.Send(waves[i]); err != nil {
+			return err
+		}
+		sent++
+	}
+
+	return nil
+}
+
+func (w *waveService) Detail(ctx context.Context, req *pb.WaveDetailRequest) (*pb.WaveDetailResponse, error) {
+	w.s.mu.Lock()
+	defer w.s.mu.Unlock()
+
+	wave, ok := w.s.waves[req.Waveid]
+	if !ok {
+		return &pb.WaveDetailResponse{ResponseCode: pb.ResponseCode_NON_EXIST}, nil
+	}
+
+	return &pb.WaveDetailResponse{ResponseCode: pb.ResponseCode_SUCCESS, Wave: proto.Clone(wave).(*pb.WaveDetailItem)}, nil
+}
@@ -94,7 +94,7 @@ func (c *client) List(ctx context.Context, groupID string) ([]Group, error) {
 
 	listCli, err := cli.List(ctx, &pb.GroupRequest{Groupid: groupID})
 	if err != nil {
-		return []Group{}, nil
+		return []Group{}, err
 	}
 
 	pbGroups := map[string]*pb.Group{}
@@ -336,12 +336,32 @@ func (c *client) Update(ctx context.Context, groupID, name, parentID string, ind
 	return err
 }
 
+// Option configures client.
+type Option func(*client)
+
+// WithConn makes client use established connection instead of dialing Ino-Vibe server.
+// Credentials are not loaded and connection is not closed by client.
+func WithConn(conn grpc.ClientConnInterface) Option {
+	return func(c *client) {
+		c.groupClient = pb.NewGroupServiceClient(conn)
+	}
+}
+
 // NewClient creates new group client.
-func NewClient() (Client, error) {
-	token, err := iv_auth.LoadCredentials()
-	if err != nil {
-		log.Panicln(err)
+func NewClient(opts ...Option) (Client, error) {
+	c := &client{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.groupClient == nil {
+		token, err := iv_auth.LoadCredentials()
+		if err != nil {
+			log.Panicln(err)
+		}
+
+		c.oauthToken = token
 	}
 
-	return &client{oauthToken: token}, nil
+	return c, nil
 }
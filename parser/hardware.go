@@ -0,0 +1,182 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+// FirmwareVersion is version of application or LoRa firmware.
+type FirmwareVersion struct {
+	Major uint
+	Minor uint
+	Rev   uint
+}
+
+// ParseFirmwareVersion parses version formatted as "major.minor.rev".
+func ParseFirmwareVersion(ver string) (FirmwareVersion, error) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(ver), "v"), ".")
+	if len(parts) != 3 {
+		return FirmwareVersion{}, ErrInvalidFormat
+	}
+
+	nums := make([]uint, len(parts))
+	for i, part := range parts {
+		num, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return FirmwareVersion{}, ErrInvalidFormat
+		}
+		nums[i] = uint(num)
+	}
+
+	return FirmwareVersion{Major: nums[0], Minor: nums[1], Rev: nums[2]}, nil
+}
+
+// Compare returns -1, 0 or 1 if v is older, same or newer than other.
+func (v FirmwareVersion) Compare(other FirmwareVersion) int {
+	for _, diff := range [][2]uint{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Rev, other.Rev}} {
+		switch {
+		case diff[0] < diff[1]:
+			return -1
+		case diff[0] > diff[1]:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func (v FirmwareVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Rev)
+}
+
+// AppFirmware returns application firmware version which alive payload reports.
+func (p *AlivePayload) AppFirmware() FirmwareVersion {
+	return FirmwareVersion{Major: p.AppFwMajor, Minor: p.AppFwMinor, Rev: p.AppFwRev}
+}
+
+// LoRaFirmware returns LoRa firmware version which alive payload reports.
+func (p *AlivePayload) LoRaFirmware() FirmwareVersion {
+	return FirmwareVersion{Major: p.LoRaFwMajor, Minor: p.LoRaFwMinor, Rev: p.LoRaFwRev}
+}
+
+// FirmwareRange is range of supported firmware versions.
+// Zero Max means no upper bound.
+type FirmwareRange struct {
+	Min FirmwareVersion
+	Max FirmwareVersion
+}
+
+// Contains returns true if version is in range.
+func (r FirmwareRange) Contains(v FirmwareVersion) bool {
+	if v.Compare(r.Min) < 0 {
+		return false
+	}
+
+	return r.Max == (FirmwareVersion{}) || v.Compare(r.Max) <= 0
+}
+
+// Hardware describes capabilities of a device type.
+type Hardware struct {
+	Type  DeviceType
+	Proto pb.DeviceType
+	Name  string
+
+	// Resolution is accelerometer resolution in mg/LSB of raw values which device reports.
+	// It does not depend on range which device reports.
+	Resolution float64
+
+	Payloads []PayloadType
+}
+
+// Supports returns true if device type sends payload type.
+func (h *Hardware) Supports(payload PayloadType) bool {
+	for _, p := range h.Payloads {
+		if p == payload {
+			return true
+		}
+	}
+
+	return false
+}
+
+var hardwares = map[DeviceType]*Hardware{
+	InoVibe: {
+		Type:       InoVibe,
+		Proto:      pb.DeviceType_InoVibe,
+		Name:       "mgi",
+		Resolution: 3.9,
+		Payloads:   []PayloadType{AliveType, EventType, ErrorType, AckType, NoticeType, DataLogType, ReportType, WaveType},
+	},
+	InoVibeS: {
+		Type:       InoVibeS,
+		Proto:      pb.DeviceType_InoVibeS,
+		Name:       "mgi_100n",
+		Resolution: 0.244,
+		Payloads: []PayloadType{AliveType, EventType, ErrorType, AckType, NoticeType, DataLogType, ReportType, WaveType,
+			InclinationType, MRMeasureType, MRReportType},
+	},
+}
+
+// LookupHardware returns hardware of device type in frame header.
+func LookupHardware(devType DeviceType) (*Hardware, bool) {
+	hw, ok := hardwares[devType]
+	return hw, ok
+}
+
+// LookupProtoHardware returns hardware of device type in API.
+func LookupProtoHardware(devType pb.DeviceType) (*Hardware, bool) {
+	for _, hw := range hardwares {
+		if hw.Proto == devType {
+			return hw, true
+		}
+	}
+
+	return nil, false
+}
+
+// Gravity returns full scale range in G.
+func (s AccSensitivity) Gravity() int {
+	return gravityOf(int(s) - 1)
+}
+
+// Gravity returns full scale range in G.
+func (r WaveBMARangeType) Gravity() int {
+	return gravityOf(int(r))
+}
+
+// Gravity returns full scale range in G.
+func (r ConfigBMARange) Gravity() int {
+	return gravityOf(int(r) - 1)
+}
+
+// gravityOf returns 2, 4, 8 or 16 of zero based range index or 0 if unknown.
+func gravityOf(idx int) int {
+	if idx < 0 || idx > 3 {
+		return 0
+	}
+
+	return 2 << uint(idx)
+}
+
+// AccelerationMg converts raw values of wave into mg.
+// ErrInvalidType returns if device type is unknown.
+func (p *WavePayload) AccelerationMg(devType DeviceType) (x, y, z []float64, err error) {
+	hw, ok := LookupHardware(devType)
+	if !ok {
+		return nil, nil, nil, ErrInvalidType
+	}
+
+	res := hw.Resolution
+	scale := func(raws []int) []float64 {
+		values := make([]float64, len(raws))
+		for i, raw := range raws {
+			values[i] = float64(raw) * res
+		}
+		return values
+	}
+
+	return scale(p.X), scale(p.Y), scale(p.Z), nil
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "bitbucket.org/ino-on/ino-vibe-api"
+)
+
+func TestLookupHardware(t *testing.T) {
+	hw, ok := LookupHardware(InoVibe)
+	assert.True(t, ok)
+	assert.Equal(t, pb.DeviceType_InoVibe, hw.Proto)
+	assert.Equal(t, 3.9, hw.Resolution)
+	assert.False(t, hw.Supports(MRReportType))
+
+	hw, ok = LookupProtoHardware(pb.DeviceType_InoVibeS)
+	assert.True(t, ok)
+	assert.Equal(t, InoVibeS, hw.Type)
+	assert.Equal(t, 0.244, hw.Resolution)
+	assert.True(t, hw.Supports(MRReportType))
+
+	_, ok = LookupHardware(DeviceType(0))
+	assert.False(t, ok)
+	_, ok = LookupProtoHardware(pb.DeviceType_InoVibePro)
+	assert.False(t, ok)
+}
+
+func TestGravity(t *testing.T) {
+	assert.Equal(t, 2, AccSensitivity2G.Gravity())
+	assert.Equal(t, 16, AccSensitivity16G.Gravity())
+	assert.Equal(t, 2, WaveBMARange2G.Gravity())
+	assert.Equal(t, 8, WaveBMARange8G.Gravity())
+	assert.Equal(t, 4, ConfigBMARange4G.Gravity())
+	assert.Equal(t, 0, ConfigBMARange(0).Gravity())
+}
+
+func TestFirmwareVersion(t *testing.T) {
+	ver, err := ParseFirmwareVersion("2.6.3")
+	assert.Nil(t, err)
+	assert.Equal(t, FirmwareVersion{Major: 2, Minor: 6, Rev: 3}, ver)
+	assert.Equal(t, "2.6.3", ver.String())
+
+	_, err = ParseFirmwareVersion("2.6")
+	assert.Equal(t, ErrInvalidFormat, err)
+	_, err = ParseFirmwareVersion("2.x.1")
+	assert.Equal(t, ErrInvalidFormat, err)
+
+	assert.Equal(t, -1, ver.Compare(FirmwareVersion{Major: 2, Minor: 10}))
+	assert.Equal(t, 1, ver.Compare(FirmwareVersion{Major: 1, Minor: 99, Rev: 99}))
+	assert.Equal(t, 0, ver.Compare(ver))
+
+	r := FirmwareRange{Min: FirmwareVersion{Major: 2}, Max: FirmwareVersion{Major: 2, Minor: 6, Rev: 3}}
+	assert.True(t, r.Contains(ver))
+	assert.False(t, r.Contains(FirmwareVersion{Major: 2, Minor: 6, Rev: 4}))
+	assert.False(t, r.Contains(FirmwareVersion{Major: 1, Minor: 9}))
+	assert.True(t, FirmwareRange{}.Contains(FirmwareVersion{Major: 99}))
+}
+
+func TestWaveAccelerationMg(t *testing.T) {
+	raw := "0302e30113009d80000d1c01ffdfffd800e8ffedffbc00e4fff6ffd300d0fff2ffe400e3fff3ffd000effff2ffe200f4ffe7fff400effff8ffee00d3ae"
+
+	parser, _ := NewFrameParser(raw)
+	header, _ := parser.Header()
+	wave, _ := parser.Wave()
+	alive := AlivePayload{AppFwMajor: 2, AppFwMinor: 6, AppFwRev: 3, LoRaFwMajor: 1, LoRaFwMinor: 2, LoRaFwRev: 2}
+	assert.Equal(t, "2.6.3", alive.AppFirmware().String())
+	assert.Equal(t, "1.2.2", alive.LoRaFirmware().String())
+
+	x, _, _, err := wave.AccelerationMg(header.DevType)
+	assert.Nil(t, err)
+	assert.Len(t, x, len(wave.X))
+	assert.Equal(t, 2, wave.Control.BMARange.Gravity())
+	assert.Equal(t, float64(wave.X[0])*3.9, x[0])
+
+	_, _, _, err = wave.AccelerationMg(DeviceType(0))
+	assert.Equal(t, ErrInvalidType, err)
+}
@@ -0,0 +1,114 @@
+package parser
+
+import "fmt"
+
+var payloadTypeNames = map[uint]string{
+	uint(AliveType):       "alive",
+	uint(EventType):       "event",
+	uint(ErrorType):       "error",
+	uint(AckType):         "ack",
+	uint(NoticeType):      "notice",
+	uint(DataLogType):     "datalog",
+	uint(ReportType):      "report",
+	uint(WaveType):        "acc_wave",
+	uint(InclinationType): "inclination",
+	uint(MRMeasureType):   "mr_measure",
+	uint(MRReportType):    "mr_report",
+}
+
+var accSensitivityNames = map[uint]string{
+	uint(AccSensitivity2G):  "2G",
+	uint(AccSensitivity4G):  "4G",
+	uint(AccSensitivity8G):  "8G",
+	uint(AccSensitivity16G): "16G",
+}
+
+var waveBMARangeNames = map[uint]string{
+	uint(WaveBMARange2G):  "2G",
+	uint(WaveBMARange4G):  "4G",
+	uint(WaveBMARange8G):  "8G",
+	uint(WaveBMARange16G): "16G",
+}
+
+var waveAxisNames = map[uint]string{
+	uint(WaveAxisXYZ): "x y z",
+	uint(WaveAxisX):   "x",
+	uint(WaveAxisY):   "y",
+	uint(WaveAxisZ):   "z",
+}
+
+var deviceSetupNames = map[uint]string{
+	uint(DeviceSetupUninstalled):    "N",
+	uint(DeviceSetupInstalled):      "Y",
+	uint(DeviceSetupPrepareInstall): "P",
+}
+
+func nameOf(names map[uint]string, value uint) string {
+	if name, ok := names[value]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", value)
+}
+
+func deviceTypeName(devType DeviceType) string {
+	if hw, ok := LookupHardware(devType); ok {
+		return hw.Name
+	}
+	return fmt.Sprintf("unknown(%d)", devType)
+}
+
+// Summary returns single line description of header.
+//
+//	V3 | Dev.: mgi | Seq.: 244 | Bat.: 17 | Temp.: 21 | Err.: 0 | Type: alive, none | RSSI.: -110 | Resv.: 100
+func (h *Header) Summary() string {
+	request := "none"
+	if h.Payload.Request != 0 {
+		request = fmt.Sprint(h.Payload.Request)
+	}
+
+	return fmt.Sprintf("V%d | Dev.: %s | Seq.: %d | Bat.: %d | Temp.: %d | Err.: %d | Type: %s, %s | RSSI.: %d | Resv.: %d",
+		h.Version, deviceTypeName(h.DevType), h.Seq, h.Battery, h.Temperature, h.LoRaErr,
+		nameOf(payloadTypeNames, uint(h.Payload.Type)), request, h.RSSI, h.Resv)
+}
+
+// Summary returns single line description of alive payload.
+//
+//	ALIVE | Acc Conf.: 2G, 1100mg | Acc Intr.: 1, 1 | Acc Vals.: 59, -249, -29 | Log: EN(XYZ), 1s, 12blks | Inst.: Y | Per: 360m | App FW: 2.6.3 | LoRa FW: 1.2.2
+func (p *AlivePayload) Summary() string {
+	logEnable := "DIS"
+	if p.LogEnable != 0 {
+		logEnable = "EN(XYZ)"
+	}
+
+	return fmt.Sprintf("ALIVE | Acc Conf.: %s, %dmg | Acc Intr.: %d, %d | Acc Vals.: %d, %d, %d | Log: %s, %ds, %dblks | Inst.: %s | Per: %dm | App FW: %d.%d.%d | LoRa FW: %d.%d.%d",
+		nameOf(accSensitivityNames, uint(p.Sensitivity)), p.Threshold, p.AccIntNo, p.AccIntData, p.X, p.Y, p.Z,
+		logEnable, p.LogInterval, p.LogBlocks, nameOf(deviceSetupNames, uint(p.Setup)), p.AlivePeriod,
+		p.AppFwMajor, p.AppFwMinor, p.AppFwRev, p.LoRaFwMajor, p.LoRaFwMinor, p.LoRaFwRev)
+}
+
+// Summary returns single line description of wave payload.
+//
+//	WAVE | Rng: 2G | Axis: x | ID: 12 | Seq: 1
+func (p *WavePayload) Summary() string {
+	return fmt.Sprintf("WAVE | Rng: %s | Axis: %s | ID: %d | Seq: %d",
+		nameOf(waveBMARangeNames, uint(p.Control.BMARange)), nameOf(waveAxisNames, uint(p.Control.Axis)), p.Control.ID, p.Position)
+}
+
+// NoticeSummary returns single line description of notice payload which Notice returns.
+func NoticeSummary(notice interface{}) string {
+	switch n := notice.(type) {
+	case PowerUp:
+		return fmt.Sprintf("NOTICE | Power Up | Reset: %d | Count: %d | Off: %d", n.ResetReason, n.Count, n.OffReason)
+	case Setup:
+		return fmt.Sprintf("NOTICE | Setup | Current: %s | Previous: %s",
+			nameOf(deviceSetupNames, uint(n.Current)), nameOf(deviceSetupNames, uint(n.Previous)))
+	case RejectCount:
+		return fmt.Sprintf("NOTICE | Reject Count | Count: %d | Period: %d | Threshold: %d", n.Count, n.Period, n.Threshold)
+	case ApplicationConfig:
+		return fmt.Sprintf("NOTICE | App Config | Mode: %d | Range: %d | High G: %dmg | Base: %d, %d, %d | MRMT: %d, %dmg, %dmg",
+			n.AppMode, n.BMARange, n.BMAHighGThresholdMg, n.BaseX, n.BaseY, n.BaseZ,
+			n.MRMTState, n.MRMTOperationThresholdMg, n.MRMTShockThresholdMg)
+	default:
+		return fmt.Sprintf("NOTICE | %+v", notice)
+	}
+}
@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummaryAlive(t *testing.T) {
+	raw := "0302f411150092100064003bff07ffe3016801044c01000101010c0102060301020223"
+
+	parser, _ := NewFrameParser(raw)
+	header, _ := parser.Header()
+	alive, _ := parser.Alive()
+
+	assert.Equal(t, "V3 | Dev.: mgi | Seq.: 244 | Bat.: 17 | Temp.: 21 | Err.: 0 | Type: alive, none | RSSI.: -110 | Resv.: 100", header.Summary())
+	assert.Equal(t, "ALIVE | Acc Conf.: 2G, 1100mg | Acc Intr.: 1, 1 | Acc Vals.: 59, -249, -29 | Log: EN(XYZ), 1s, 12blks | Inst.: Y | Per: 360m | App FW: 2.6.3 | LoRa FW: 1.2.2", alive.Summary())
+}
+
+func TestSummaryWave(t *testing.T) {
+	fixtures := []struct {
+		Raw     string
+		Header  string
+		Payload string
+	}{
+		{
+			Raw:     "0302e30113009d80000d1c01ffdfffd800e8ffedffbc00e4fff6ffd300d0fff2ffe400e3fff3ffd000effff2ffe200f4ffe7fff400effff8ffee00d3ae",
+			Header:  "V3 | Dev.: mgi | Seq.: 227 | Bat.: 1 | Temp.: 19 | Err.: 0 | Type: acc_wave, none | RSSI.: -99 | Resv.: 13",
+			Payload: "WAVE | Rng: 2G | Axis: x | ID: 12 | Seq: 1",
+		},
+		{
+			Raw:     "0303db5c1d00a2800a2604ff0fc2fe6dfef80fdafe8afee10fe3fe6afefb100ffe6cfee3100ffe80fee51007fe6cfeea0ff3fe78feec0fdefe72fed367",
+			Header:  "V3 | Dev.: mgi_100n | Seq.: 219 | Bat.: 92 | Temp.: 29 | Err.: 0 | Type: acc_wave, none | RSSI.: -94 | Resv.: 2598",
+			Payload: "WAVE | Rng: 2G | Axis: x y z | ID: 4 | Seq: 15",
+		},
+	}
+
+	for _, fixture := range fixtures {
+		parser, _ := NewFrameParser(fixture.Raw)
+		header, _ := parser.Header()
+		wave, _ := parser.Wave()
+
+		assert.Equal(t, fixture.Header, header.Summary())
+		assert.Equal(t, fixture.Payload, wave.Summary())
+	}
+}
+
+func TestSummaryNotice(t *testing.T) {
+	assert.Equal(t, "NOTICE | Setup | Current: Y | Previous: P",
+		NoticeSummary(Setup{Type: NoticeSetup, Current: DeviceSetupInstalled, Previous: DeviceSetupPrepareInstall}))
+}
@@ -54,11 +54,33 @@ func (c *client) Reset(ctx context.Context, devid string) error {
 }
 
 func (c *client) Close() {
-	c.conn.Close()
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+// Option configures client.
+type Option func(*client)
+
+// WithConn makes client use established connection instead of dialing Ino-Vibe server.
+// Credentials are not loaded and connection is not closed by client.
+func WithConn(conn grpc.ClientConnInterface) Option {
+	return func(c *client) {
+		c.thingplugClient = pb.NewThingplugServiceClient(conn)
+	}
 }
 
 // NewClient create new client.
-func NewClient() (Client, error) {
+func NewClient(opts ...Option) (Client, error) {
+	c := &client{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.thingplugClient != nil {
+		return c, nil
+	}
+
 	token, err := iv_auth.LoadCredentials()
 	if err != nil {
 		return nil, err
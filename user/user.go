@@ -35,6 +35,7 @@ type DeviceToken struct {
 
 type client struct {
 	oauthToken *oauth2.Token
+	conn       grpc.ClientConnInterface
 }
 
 var (
@@ -43,7 +44,7 @@ var (
 
 // RegisterDeviceToken register device token to receive mobile push notification.
 func (c *client) RegisterDeviceToken(userID, username, deviceToken string) error {
-	if c.oauthToken == nil {
+	if c.conn == nil && c.oauthToken == nil {
 		log.Panicln(errors.New("No credentials"))
 	}
 
@@ -68,7 +69,11 @@ func (c *client) RegisterDeviceToken(userID, username, deviceToken string) error
 	return err
 }
 
-func (c *client) connection() (*grpc.ClientConn, error) {
+func (c *client) connection() (grpc.ClientConnInterface, error) {
+	if c.conn != nil {
+		return c.conn, nil
+	}
+
 	certPool, err := x509.SystemCertPool()
 	if err != nil {
 		log.Panicln(err)
@@ -85,7 +90,7 @@ func (c *client) connection() (*grpc.ClientConn, error) {
 }
 
 func (c *client) GetDeviceToken(username string) ([]DeviceToken, error) {
-	if c.oauthToken == nil {
+	if c.conn == nil && c.oauthToken == nil {
 		log.Panicln(errors.New("No credentials"))
 	}
 
@@ -118,12 +123,32 @@ func (c *client) GetDeviceToken(username string) ([]DeviceToken, error) {
 	return deviceTokens, nil
 }
 
+// Option configures client.
+type Option func(*client)
+
+// WithConn makes client use established connection instead of dialing Ino-Vibe server.
+// Credentials are not loaded and connection is not closed by client.
+func WithConn(conn grpc.ClientConnInterface) Option {
+	return func(c *client) {
+		c.conn = conn
+	}
+}
+
 // NewClient creates client.
-func NewClient() (Client, error) {
-	token, err := iv_auth.LoadCredentials()
-	if err != nil {
-		log.Panicln(err)
+func NewClient(opts ...Option) (Client, error) {
+	c := &client{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.conn == nil {
+		token, err := iv_auth.LoadCredentials()
+		if err != nil {
+			log.Panicln(err)
+		}
+
+		c.oauthToken = token
 	}
 
-	return &client{oauthToken: token}, nil
+	return c, nil
 }
@@ -60,11 +60,33 @@ func (c *client) Detail(ctx context.Context, req *pb.WaveDetailRequest) (*pb.Wav
 }
 
 func (c *client) Close() {
-	_ = c.conn.Close()
+	if c.conn != nil {
+		_ = c.conn.Close()
+	}
+}
+
+// Option configures client.
+type Option func(*client)
+
+// WithConn makes client use established connection instead of dialing Ino-Vibe server.
+// Connection is not closed by client.
+func WithConn(conn grpc.ClientConnInterface) Option {
+	return func(c *client) {
+		c.waveClient = pb.NewWaveServiceClient(conn)
+	}
 }
 
 // NewClient creates new client instance.
-func NewClient() (Client, error) {
+func NewClient(opts ...Option) (Client, error) {
+	c := &client{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.waveClient != nil {
+		return c, nil
+	}
+
 	token, err := iv_auth.LoadCredentials()
 	if err != nil {
 		return nil, err